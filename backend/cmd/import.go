@@ -0,0 +1,130 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/audit"
+	"backend/internal/db"
+	repository2 "backend/internal/repository"
+	service2 "backend/internal/service"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runImportMedications implements "import-medications [-dry-run] [-format csv|json] <file>".
+// It prints the JSON report and returns a non-zero exit code if any row failed.
+func runImportMedications(args []string) int {
+	flags := flag.NewFlagSet("import-medications", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report what would be created or updated")
+	format := flags.String("format", "", "csv or json (default: from the file extension)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import-medications [-dry-run] [-format csv|json] <file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open import file: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	config.Load()
+	if err := db.Connect(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	database := db.GetDB()
+	auditService := service2.NewAuditService(repository2.NewAuditEventRepository(database))
+	importService := service2.NewMedicationImportService(repository2.NewMedicationRepository(database), auditService)
+
+	ctx := audit.WithActor(context.Background(), audit.Actor{UserAgent: "cli import-medications"})
+	report, err := importService.Import(ctx, *format, file, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runImportInteractions implements "import-interactions [-dry-run] <file.csv>" with the same
+// output and exit codes as runImportMedications
+func runImportInteractions(args []string) int {
+	flags := flag.NewFlagSet("import-interactions", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import-interactions [-dry-run] <file.csv>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open import file: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	config.Load()
+	if err := db.Connect(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	database := db.GetDB()
+	interactionService := service2.NewInteractionService(repository2.NewIngredientRepository(database), repository2.NewMedicationRepository(database))
+
+	report, err := interactionService.ImportInteractions(context.Background(), file, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
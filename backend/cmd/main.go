@@ -2,9 +2,14 @@ package main
 
 import (
 	"backend/config"
+	"backend/internal/auth"
 	"backend/internal/db"
+	"backend/internal/jobs"
 	"backend/internal/router"
+	"context"
 	"log"
+	"os"
+	_ "time/tzdata" // the runtime image ships without zoneinfo, profile timezones need it
 )
 
 // @title           DoseLog API
@@ -18,13 +23,30 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import-medications":
+			os.Exit(runImportMedications(os.Args[2:]))
+		case "import-interactions":
+			os.Exit(runImportInteractions(os.Args[2:]))
+		}
+	}
+
 	config.Load()
 
+	if err := auth.LoadKeys(); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
 	if err := db.Connect(); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
 	r := router.SetupRouter()
 
 	log.Printf("Server starting on port %s", config.ServerPort)
@@ -1,15 +1,93 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OIDCProvider is an OpenID Connect issuer users can sign in with
+type OIDCProvider struct {
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// devJWTSecret signs tokens when DEV_MODE is on and no JWT_SECRET is set; it is public, so never use it in production
+const devJWTSecret = "your-secret-key-change-this-in-production"
 
 var (
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	JWTSecret  string
-	ServerPort string
+	// DevMode allows insecure development defaults such as the built-in JWT secret
+	DevMode bool
+
+	// AdminEmails are promoted to admin when they log in with a verified address, so a fresh
+	// install can get its first admin without editing the database
+	AdminEmails []string
+
+	DBHost          string
+	DBPort          string
+	DBUser          string
+	DBPassword      string
+	DBName          string
+	JWTSecret       string
+	JWTIssuer       string
+	JWTAudience     string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	ServerPort      string
+	AppBaseURL      string
+
+	MailDriver   string
+	MailFrom     string
+	MailLogDir   string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	PasswordResetTTL         time.Duration
+	EmailVerificationTTL     time.Duration
+	EmailChangeTTL           time.Duration
+	RequireEmailVerification bool
+
+	AccountDeletionGracePeriod time.Duration
+
+	TOTPIssuer            string
+	TwoFactorChallengeTTL time.Duration
+
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LoginFailureWindow      time.Duration
+	LoginLockoutBase        time.Duration
+	LoginLockoutMax         time.Duration
+	AccountUnlockTTL        time.Duration
+
+	CareInviteTTL time.Duration
+
+	JWTSigningKeyFile       string
+	JWTVerificationKeyFiles []string
+
+	// AuditRetention is how long audit events are kept; zero keeps them forever
+	AuditRetention time.Duration
+
+	OIDCProviders []OIDCProvider
+	OIDCStateTTL  time.Duration
+
+	StorageDriver     string
+	StorageLocalDir   string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3ForcePathStyle  bool
+
+	AttachmentMaxImageBytes    int
+	AttachmentMaxDocumentBytes int
 )
 
 func Load() {
@@ -18,8 +96,88 @@ func Load() {
 	DBUser = getEnv("DB_USER", "doselog_user")
 	DBPassword = getEnv("DB_PASSWORD", "doselog_pass")
 	DBName = getEnv("DB_NAME", "doselog_db")
-	JWTSecret = getEnv("JWT_SECRET", "your-secret-key-change-this-in-production")
+	DevMode = getEnvBool("DEV_MODE", false)
+	AdminEmails = strings.FieldsFunc(getEnv("ADMIN_EMAILS", ""), func(r rune) bool { return r == ',' || r == ' ' })
+	JWTSecret = getEnv("JWT_SECRET", "")
+	if JWTSecret == "" && DevMode {
+		JWTSecret = devJWTSecret
+	}
+	AccessTokenTTL = getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+	RefreshTokenTTL = getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
 	ServerPort = getEnv("SERVER_PORT", "8080")
+	AppBaseURL = getEnv("APP_BASE_URL", "http://localhost:"+ServerPort)
+	JWTIssuer = getEnv("JWT_ISSUER", AppBaseURL)
+	JWTAudience = getEnv("JWT_AUDIENCE", "doselog-api")
+
+	MailDriver = getEnv("MAIL_DRIVER", "log")
+	MailFrom = getEnv("MAIL_FROM", "DoseLog <no-reply@doselog.local>")
+	MailLogDir = getEnv("MAIL_LOG_DIR", "")
+	SMTPHost = getEnv("SMTP_HOST", "localhost")
+	SMTPPort = getEnv("SMTP_PORT", "587")
+	SMTPUsername = getEnv("SMTP_USERNAME", "")
+	SMTPPassword = getEnv("SMTP_PASSWORD", "")
+
+	PasswordResetTTL = getEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	EmailVerificationTTL = getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	EmailChangeTTL = getEnvDuration("EMAIL_CHANGE_TTL", 24*time.Hour)
+	RequireEmailVerification = getEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+
+	AccountDeletionGracePeriod = getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+
+	TOTPIssuer = getEnv("TOTP_ISSUER", "DoseLog")
+	TwoFactorChallengeTTL = getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute)
+
+	LoginMaxAccountFailures = getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5)
+	LoginMaxIPFailures = getEnvInt("LOGIN_MAX_IP_FAILURES", 20)
+	LoginFailureWindow = getEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour)
+	LoginLockoutBase = getEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute)
+	LoginLockoutMax = getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour)
+	AccountUnlockTTL = getEnvDuration("ACCOUNT_UNLOCK_TTL", 24*time.Hour)
+
+	CareInviteTTL = getEnvDuration("CARE_INVITE_TTL", 7*24*time.Hour)
+
+	JWTSigningKeyFile = getEnv("JWT_SIGNING_KEY_FILE", "")
+	JWTVerificationKeyFiles = strings.FieldsFunc(getEnv("JWT_VERIFICATION_KEY_FILES", ""), func(r rune) bool { return r == ',' })
+
+	AuditRetention = getEnvDuration("AUDIT_RETENTION", 2*365*24*time.Hour)
+
+	OIDCProviders = loadOIDCProviders()
+	OIDCStateTTL = getEnvDuration("OIDC_STATE_TTL", 10*time.Minute)
+
+	StorageDriver = getEnv("STORAGE_DRIVER", "local")
+	StorageLocalDir = getEnv("STORAGE_LOCAL_DIR", "uploads")
+	S3Endpoint = getEnv("S3_ENDPOINT", "")
+	S3Region = getEnv("S3_REGION", "us-east-1")
+	S3Bucket = getEnv("S3_BUCKET", "doselog")
+	S3AccessKeyID = getEnv("S3_ACCESS_KEY_ID", "")
+	S3SecretAccessKey = getEnv("S3_SECRET_ACCESS_KEY", "")
+	S3ForcePathStyle = getEnvBool("S3_FORCE_PATH_STYLE", false)
+
+	AttachmentMaxImageBytes = getEnvInt("ATTACHMENT_MAX_IMAGE_BYTES", 5<<20)
+	AttachmentMaxDocumentBytes = getEnvInt("ATTACHMENT_MAX_DOCUMENT_BYTES", 20<<20)
+}
+
+// loadOIDCProviders reads OIDC_PROVIDERS (comma separated names) and the
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES and _DISPLAY_NAME keys of each
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			DisplayName:  getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       strings.TrimSuffix(getEnv(prefix+"ISSUER", ""), "/"),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
 }
 
 func getEnv(key, defaultValue string) string {
@@ -28,3 +186,30 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Actor describes who performed a request, as far as the request tells
+type Actor struct {
+	UserID    *uuid.UUID
+	SessionID *uuid.UUID
+	TokenID   *uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the actor, for services that record audit events
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored in ctx; background jobs have none and get the zero Actor
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// Middleware copies the authenticated identity and client details into the request context.
+// It must run after auth.AuthMiddleware, which sets userID, sessionID and tokenID.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := Actor{
+			UserID:    contextUUID(c, "userID"),
+			SessionID: contextUUID(c, "sessionID"),
+			TokenID:   contextUUID(c, "tokenID"),
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
+
+func contextUUID(c *gin.Context, key string) *uuid.UUID {
+	value, exists := c.Get(key)
+	if !exists {
+		return nil
+	}
+	id, ok := value.(uuid.UUID)
+	if !ok || id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
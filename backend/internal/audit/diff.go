@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Change is the old and new value of one field
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Snapshot flattens an entity into column name -> value using its db tags, so audit documents
+// use the same names as the tables. Fields without a db tag are skipped; nil yields nil.
+func Snapshot(v interface{}) map[string]interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	snapshot := make(map[string]interface{}, rv.NumField())
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		snapshot[name] = rv.Field(i).Interface()
+	}
+	return snapshot
+}
+
+// Diff lists the fields whose JSON encoding differs between two snapshots
+func Diff(before, after map[string]interface{}) map[string]Change {
+	changes := make(map[string]Change)
+	for name, newValue := range after {
+		oldValue, existed := before[name]
+		if existed && sameJSON(oldValue, newValue) {
+			continue
+		}
+		changes[name] = Change{From: oldValue, To: newValue}
+	}
+	for name, oldValue := range before {
+		if _, exists := after[name]; !exists {
+			changes[name] = Change{From: oldValue, To: nil}
+		}
+	}
+	return changes
+}
+
+func sameJSON(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(encodedA, encodedB)
+}
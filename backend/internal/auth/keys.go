@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"backend/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tokens are signed with one current key and verified against every configured key, picked by
+// the kid header. To rotate without downtime: add the new public key to JWT_VERIFICATION_KEY_FILES
+// on every instance, then make it the JWT_SIGNING_KEY_FILE, and drop the old key once the
+// longest-lived token signed with it has expired.
+//
+// Without a signing key the server falls back to HS256 with JWT_SECRET, which other services
+// cannot verify and which publishes no keys on /.well-known/jwks.json. With neither set the server
+// refuses to start unless DEV_MODE allows the built-in development secret.
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type keyRing struct {
+	signer    *signingKey
+	verifiers map[string]*verificationKey
+	order     []string
+}
+
+var (
+	ringMu sync.RWMutex
+	ring   *keyRing
+)
+
+// LoadKeys reads the signing and verification keys named in config. It must run after config.Load.
+func LoadKeys() error {
+	loaded := &keyRing{verifiers: make(map[string]*verificationKey)}
+
+	if config.JWTSigningKeyFile != "" {
+		signer, err := readSigningKey(config.JWTSigningKeyFile)
+		if err != nil {
+			return err
+		}
+		loaded.signer = signer
+		loaded.add(&verificationKey{kid: signer.kid, method: signer.method, key: signer.key.Public()})
+	} else {
+		if config.JWTSecret == "" {
+			return fmt.Errorf("neither JWT_SIGNING_KEY_FILE nor JWT_SECRET is set; set DEV_MODE=true to use the built-in development secret")
+		}
+		log.Printf("JWT_SIGNING_KEY_FILE is not set, signing tokens with HS256 and JWT_SECRET")
+		if os.Getenv("JWT_SECRET") == "" {
+			log.Printf("DEV_MODE is on and JWT_SECRET is not set, tokens are signed with the built-in development secret")
+		}
+	}
+
+	for _, path := range config.JWTVerificationKeyFiles {
+		key, err := readVerificationKey(path)
+		if err != nil {
+			return err
+		}
+		loaded.add(key)
+	}
+
+	ringMu.Lock()
+	ring = loaded
+	ringMu.Unlock()
+	return nil
+}
+
+func (r *keyRing) add(key *verificationKey) {
+	if _, exists := r.verifiers[key.kid]; exists {
+		return
+	}
+	r.verifiers[key.kid] = key
+	r.order = append(r.order, key.kid)
+}
+
+func currentRing() *keyRing {
+	ringMu.RLock()
+	defer ringMu.RUnlock()
+	if ring == nil {
+		return &keyRing{verifiers: map[string]*verificationKey{}}
+	}
+	return ring
+}
+
+// signToken signs claims with the current key, setting its kid header
+func signToken(claims jwt.Claims) (string, error) {
+	r := currentRing()
+	if r.signer == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.JWTSecret))
+	}
+
+	token := jwt.NewWithClaims(r.signer.method, claims)
+	token.Header["kid"] = r.signer.kid
+	return token.SignedString(r.signer.key)
+}
+
+// verificationKeyFunc resolves the key for a token from its kid header. HS256 tokens are only
+// accepted while no asymmetric signing key is configured.
+func verificationKeyFunc(token *jwt.Token) (interface{}, error) {
+	r := currentRing()
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if r.signer != nil {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.JWTSecret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.verifiers[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.key, nil
+}
+
+// JSONWebKey is a public key as published on /.well-known/jwks.json (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeys returns every key tokens may currently be verified with
+func PublicKeys() JSONWebKeySet {
+	r := currentRing()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, kid := range r.order {
+		key := r.verifiers[kid]
+		jwk := JSONWebKey{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.key.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func readSigningKey(path string) (*signingKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q, expected a private key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse private key: %w", path, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported private key type", path)
+	}
+	method, err := methodFor(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	kid, err := keyID(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &signingKey{kid: kid, method: method, key: signer}, nil
+}
+
+// readVerificationKey accepts a public key or, for convenience, the private key file itself
+func readVerificationKey(path string) (*verificationKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var public crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to parse public key: %w", path, err)
+		}
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to parse public key: %w", path, err)
+		}
+	default:
+		signer, err := readSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		public = signer.key.Public()
+	}
+
+	method, err := methodFor(public)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	kid, err := keyID(public)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &verificationKey{kid: kid, method: method, key: public}, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+func methodFor(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected Ed25519 or RSA", public)
+	}
+}
+
+// keyID derives a stable kid from the public key so every instance computes the same value
+func keyID(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
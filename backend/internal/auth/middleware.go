@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"backend/internal/core/shared"
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,7 +10,14 @@ import (
 	"github.com/google/uuid"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator reports whether the session an access token was issued for is still usable
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// AuthMiddleware accepts either a session-bound JWT or a personal access token.
+// Token requests carry "scopes" in the context and must pass RequireScope checks.
+func AuthMiddleware(sessions SessionValidator, tokens TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -26,6 +35,28 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
+		if isPersonalAccessToken(tokenString) {
+			identity, err := tokens.ValidateAccessToken(c.Request.Context(), tokenString, c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate token"})
+				c.Abort()
+				return
+			}
+			if identity == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid, expired or revoked token"})
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", identity.UserID)
+			c.Set("role", identity.Role)
+			c.Set("tokenID", identity.TokenID)
+			c.Set("scopes", identity.Scopes)
+
+			c.Next()
+			return
+		}
+
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
@@ -33,26 +64,48 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		active, err := sessions.IsSessionActive(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("role", claims.Role)
 
 		c.Next()
 	}
 }
 
-// RequireResourceOwnership checks if the authenticated user owns the resource
-func RequireResourceOwnership(c *gin.Context, resourceUserID uuid.UUID) bool {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		c.Abort()
-		return false
+// CurrentRole returns the role of the authenticated user, defaulting to the least privileged one
+func CurrentRole(c *gin.Context) shared.Role {
+	if role, ok := c.Get("role"); ok {
+		if r, ok := role.(shared.Role); ok && r != "" {
+			return r
+		}
 	}
+	return shared.RoleUser
+}
 
-	if userID.(uuid.UUID) != resourceUserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+// RequireRole allows the request through only if the authenticated user has one of the roles
+func RequireRole(roles ...shared.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := CurrentRole(c)
+		for _, role := range roles {
+			if current == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient role"})
 		c.Abort()
-		return false
 	}
-
-	return true
 }
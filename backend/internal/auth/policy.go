@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Action is what a request wants to do with data owned by a user
+type Action string
+
+const (
+	// ActionRead covers listing and viewing a user's medications, logs and stats
+	ActionRead Action = "read"
+	// ActionManage covers recording intake on a user's behalf
+	ActionManage Action = "manage"
+	// ActionOwner is reserved to the owner and is never delegated
+	ActionOwner Action = "owner"
+)
+
+// GrantChecker reports whether actorID has been granted the action on ownerID's data
+type GrantChecker interface {
+	CanAccess(ctx context.Context, ownerID, actorID uuid.UUID, action Action) (bool, error)
+}
+
+// Policy decides whether the authenticated user may act on another user's resources.
+// Owners may do everything; anyone else needs a grant that covers the action.
+type Policy struct {
+	grants GrantChecker
+}
+
+func NewPolicy(grants GrantChecker) *Policy {
+	return &Policy{grants: grants}
+}
+
+// Authorize writes a 401/403 response and aborts when the authenticated user may not perform the action
+func (p *Policy) Authorize(c *gin.Context, ownerID uuid.UUID, action Action) bool {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return false
+	}
+
+	actorID := userID.(uuid.UUID)
+	if actorID == ownerID {
+		return true
+	}
+
+	if action != ActionOwner && p.grants != nil {
+		allowed, err := p.grants.CanAccess(c.Request.Context(), ownerID, actorID, action)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access"})
+			c.Abort()
+			return false
+		}
+		if allowed {
+			return true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+	c.Abort()
+	return false
+}
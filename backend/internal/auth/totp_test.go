@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 4226 and RFC 6238 test vectors
+const rfcSecret = "12345678901234567890"
+
+// TestHOTP uses the vectors of RFC 4226 appendix D
+func TestHOTP(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		if got := hotp([]byte(rfcSecret), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// TestValidateTOTP uses the SHA-1 vectors of RFC 6238 appendix B. The RFC lists eight digit
+// codes; six digit codes are their last six digits.
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "94287082"},
+		{unix: 1111111109, code: "07081804"},
+		{unix: 1111111111, code: "14050471"},
+		{unix: 1234567890, code: "89005924"},
+		{unix: 2000000000, code: "69279037"},
+		{unix: 20000000000, code: "65353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		code := tt.code[len(tt.code)-totpDigits:]
+
+		step, ok := ValidateTOTP(secret, code, now)
+		if !ok {
+			t.Errorf("ValidateTOTP(%s, %d) rejected the RFC code", code, tt.unix)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(%s, %d) step = %d, want %d", code, tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	// 287082 is the code of step 1 (RFC 6238 time 59)
+	tests := []struct {
+		unix int64
+		want bool
+	}{
+		{unix: 0, want: true},
+		{unix: 59, want: true},
+		{unix: 60, want: true},
+		{unix: 89, want: true},
+		{unix: 90, want: false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := ValidateTOTP(secret, "287082", time.Unix(tt.unix, 0)); ok != tt.want {
+			t.Errorf("ValidateTOTP(287082, %d) = %v, want %v", tt.unix, ok, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		want   bool
+	}{
+		{name: "surrounding spaces", secret: secret, code: " 287082 ", want: true},
+		{name: "lower case secret", secret: strings.ToLower(secret), code: "287082", want: true},
+		{name: "wrong code", secret: secret, code: "287083", want: false},
+		{name: "eight digits", secret: secret, code: "94287082", want: false},
+		{name: "empty code", secret: secret, code: "", want: false},
+		{name: "invalid secret", secret: "not base32!", code: "287082", want: false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.want {
+			t.Errorf("%s: ValidateTOTP() = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestGeneratedSecretValidates(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("GenerateTOTPSecret() = %s, want 20 bytes of unpadded base32", secret)
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, hotp(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("ValidateTOTP() rejected the current code of a generated secret")
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "abcde-fghij", want: "abcde-fghij"},
+		{code: "ABCDE FGHIJ", want: "abcde-fghij"},
+		{code: "abcdefghij", want: "abcde-fghij"},
+		{code: "abc", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
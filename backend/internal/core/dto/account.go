@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type AccountDeletionResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Status       string     `json:"status"` // "pending", "cancelled", "completed"
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+type DataExportResponse struct {
+	ExportedAt       time.Time                  `json:"exported_at"`
+	User             UserResponse               `json:"user"`
+	Sessions         []*SessionResponse         `json:"sessions"`
+	Profiles         []*ProfileResponse         `json:"profiles"`
+	Medications      []*MedicationResponse      `json:"medications"`
+	UserMedications  []*UserMedicationResponse  `json:"user_medications"`
+	MedicationLogs   []*MedicationLogResponse   `json:"medication_logs"`
+	DeletionRequests []*AccountDeletionResponse `json:"account_deletion_requests"`
+	CareGrants       []*CareGrantResponse       `json:"care_grants"`
+	AuditEvents      []*AuditEventResponse      `json:"audit_events"`
+}
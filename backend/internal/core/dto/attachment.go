@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MedicationAttachmentResponse struct {
+	ID           uuid.UUID             `json:"id"`
+	MedicationID uuid.UUID             `json:"medication_id"`
+	Kind         shared.AttachmentKind `json:"kind"`
+	FileName     string                `json:"file_name"`
+	ContentType  string                `json:"content_type"`
+	SizeBytes    int64                 `json:"size_bytes"`
+	HasThumbnail bool                  `json:"has_thumbnail"`
+	UploadedBy   *uuid.UUID            `json:"uploaded_by,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEventResponse struct {
+	ID            uuid.UUID          `json:"id"`
+	OccurredAt    time.Time          `json:"occurred_at"`
+	ActorID       *uuid.UUID         `json:"actor_id,omitempty"`
+	SubjectUserID *uuid.UUID         `json:"subject_user_id,omitempty"`
+	SessionID     *uuid.UUID         `json:"session_id,omitempty"`
+	TokenID       *uuid.UUID         `json:"token_id,omitempty"`
+	IPAddress     *string            `json:"ip_address,omitempty"`
+	UserAgent     *string            `json:"user_agent,omitempty"`
+	Action        shared.AuditAction `json:"action"`
+	EntityType    shared.AuditEntity `json:"entity_type"`
+	EntityID      uuid.UUID          `json:"entity_id"`
+	Before        json.RawMessage    `json:"before,omitempty" swaggertype:"object"`
+	After         json.RawMessage    `json:"after,omitempty" swaggertype:"object"`
+	Changes       json.RawMessage    `json:"changes,omitempty" swaggertype:"object"`
+}
+
+// AuditEventQuery filters the audit trail; empty fields match everything
+type AuditEventQuery struct {
+	UserID     *uuid.UUID
+	EntityType shared.AuditEntity
+	EntityID   *uuid.UUID
+	Action     shared.AuditAction
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
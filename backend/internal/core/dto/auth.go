@@ -1,11 +1,47 @@
 package dto
 
+import "time"
+
 type LoginRequest struct {
-	Email    string `json:"email"    validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string  `json:"email"                 validate:"required,email"`
+	Password   string  `json:"password"              validate:"required"`
+	DeviceName *string `json:"device_name,omitempty" validate:"omitempty,max=100"`
 }
 
+// LoginResponse either carries the issued tokens or, when TwoFactorRequired is set,
+// a challenge token to exchange at /auth/2fa/verify
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token             string       `json:"token,omitempty"`
+	ExpiresAt         *time.Time   `json:"expires_at,omitempty"`
+	RefreshToken      string       `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool         `json:"two_factor_required"`
+	ChallengeToken    string       `json:"challenge_token,omitempty"`
+	User              UserResponse `json:"user"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenResponse struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"        validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
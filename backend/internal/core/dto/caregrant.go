@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CareGrantInviteRequest struct {
+	Email      string                `json:"email"      validate:"required,email"`
+	Permission shared.CarePermission `json:"permission" validate:"required,oneof=read manage"`
+}
+
+type CareGrantAcceptRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CareGrantResponse struct {
+	ID             uuid.UUID             `json:"id"`
+	PatientID      uuid.UUID             `json:"patient_id"`
+	PatientEmail   string                `json:"patient_email,omitempty"`
+	CaregiverEmail string                `json:"caregiver_email"`
+	CaregiverID    *uuid.UUID            `json:"caregiver_id"`
+	Permission     shared.CarePermission `json:"permission"`
+	Status         string                `json:"status"` // "pending", "active", "expired", "revoked"
+	InviteExpires  time.Time             `json:"invite_expires_at"`
+	AcceptedAt     *time.Time            `json:"accepted_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
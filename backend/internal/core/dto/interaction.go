@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+
+	"github.com/google/uuid"
+)
+
+type MedicationIngredientRequest struct {
+	Name     string   `json:"name"                validate:"required,max=200"`
+	AmountMg *float32 `json:"amount_mg,omitempty" validate:"omitempty,gt=0"`
+}
+
+type MedicationIngredientsRequest struct {
+	Ingredients []MedicationIngredientRequest `json:"ingredients" validate:"dive"`
+}
+
+type MedicationIngredientResponse struct {
+	IngredientID uuid.UUID `json:"ingredient_id"`
+	Name         string    `json:"name"`
+	AmountMg     *float32  `json:"amount_mg"`
+}
+
+// InteractionWarning describes an interaction between a medication being started and one already taken
+type InteractionWarning struct {
+	MedicationID    uuid.UUID                  `json:"medication_id"`
+	MedicationName  string                     `json:"medication_name"`
+	Ingredient      string                     `json:"ingredient"`
+	OtherIngredient string                     `json:"other_ingredient"`
+	Severity        shared.InteractionSeverity `json:"severity"`
+	Description     string                     `json:"description"`
+}
+
+type InteractionImportRowError struct {
+	Row         int      `json:"row"`
+	IngredientA string   `json:"ingredient_a,omitempty"`
+	IngredientB string   `json:"ingredient_b,omitempty"`
+	Errors      []string `json:"errors"`
+}
+
+// InteractionImportReport summarises an interaction import; only failed rows are listed
+type InteractionImportReport struct {
+	DryRun   bool                         `json:"dry_run"`
+	Total    int                          `json:"total"`
+	Imported int                          `json:"imported"`
+	Failed   int                          `json:"failed"`
+	Errors   []*InteractionImportRowError `json:"errors"`
+}
@@ -12,28 +12,115 @@ type MedicationCreateRequest struct {
 	Description  *string             `json:"description,omitempty"   validate:"omitempty,min=2"`
 	Manufacturer *string             `json:"manufacturer,omitempty"  validate:"omitempty"`
 	Form         string              `json:"form"                    validate:"required,oneof=tablet capsule syrup drop injection"`
-	PillsPerBox  int                 `json:"pills_per_box"           validate:"required,min=1"`
-	StrengthMg   int                 `json:"strength_mg"             validate:"required,gt=0"`
+	Strength     float32             `json:"strength"                validate:"required,gt=0"`
+	StrengthUnit shared.DoseUnit     `json:"strength_unit,omitempty" validate:"omitempty,oneof=mg mcg g iu"`
+	PackageSize  float32             `json:"package_size"            validate:"required,gt=0"`
+	PackageUnit  shared.DoseUnit     `json:"package_unit,omitempty"  validate:"omitempty,oneof=units ml drops puffs g"`
 	MealRelation shared.MealRelation `json:"meal_relation"           validate:"required,oneof=before_meal after_meal with_meal irrelevant"`
+	ExternalCode *string             `json:"external_code,omitempty" validate:"omitempty,max=64"`
+	// Barcodes are GTIN/EAN codes of the box; they are returned as 14-digit GTINs
+	Barcodes []string `json:"barcodes,omitempty" validate:"omitempty,max=20"`
+	// Visibility defaults to shared; private entries are only seen by their creator and skip review
+	Visibility shared.MedicationVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=shared private"`
+	// AllowDuplicates creates the entry even when probable duplicates exist
+	AllowDuplicates bool `json:"allow_duplicates,omitempty"`
+
+	// Deprecated: StrengthMg is the strength in mg sent by older clients; use strength
+	StrengthMg *float32 `json:"strength_mg,omitempty" validate:"omitempty,gt=0"`
+	// Deprecated: PillsPerBox is the package size sent by older clients; use package_size
+	PillsPerBox *float32 `json:"pills_per_box,omitempty" validate:"omitempty,gt=0"`
 }
 
 type MedicationUpdateRequest struct {
 	Name         *string              `json:"name,omitempty"          validate:"omitempty,min=2"`
 	Form         *string              `json:"form,omitempty"          validate:"omitempty,oneof=tablet capsule syrup drop injection"`
-	StrengthMg   *int                 `json:"strength_mg,omitempty"   validate:"omitempty,gt=0"`
+	Strength     *float32             `json:"strength,omitempty"      validate:"omitempty,gt=0"`
+	StrengthUnit *shared.DoseUnit     `json:"strength_unit,omitempty" validate:"omitempty,oneof=mg mcg g iu"`
+	PackageSize  *float32             `json:"package_size,omitempty"  validate:"omitempty,gt=0"`
+	PackageUnit  *shared.DoseUnit     `json:"package_unit,omitempty"  validate:"omitempty,oneof=units ml drops puffs g"`
 	MealRelation *shared.MealRelation `json:"meal_relation,omitempty" validate:"omitempty,oneof=before_meal after_meal with_meal irrelevant"`
 	Manufacturer *string              `json:"manufacturer,omitempty"  validate:"omitempty,min=2"`
 	Description  *string              `json:"description,omitempty"   validate:"omitempty,min=2"`
+	ExternalCode *string              `json:"external_code,omitempty" validate:"omitempty,max=64"`
+	// Barcodes replaces every barcode of the entry when present
+	Barcodes *[]string `json:"barcodes,omitempty" validate:"omitempty,max=20"`
+
+	// Deprecated: StrengthMg is the strength in mg sent by older clients; use strength
+	StrengthMg *float32 `json:"strength_mg,omitempty" validate:"omitempty,gt=0"`
+	// Deprecated: PillsPerBox is the package size sent by older clients; use package_size
+	PillsPerBox *float32 `json:"pills_per_box,omitempty" validate:"omitempty,gt=0"`
 }
 
 type MedicationResponse struct {
-	ID           uuid.UUID           `json:"id"`
-	Name         string              `json:"name"`
-	Description  *string             `json:"description"`
-	Manufacturer *string             `json:"manufacturer"`
-	Form         string              `json:"form"`
-	StrengthMg   int                 `json:"strength_mg"`
-	PillsPerBox  int                 `json:"pills_per_box"`
-	MealRelation shared.MealRelation `json:"meal_relation"`
-	CreatedAt    time.Time           `json:"created_at"`
+	ID           uuid.UUID                   `json:"id"`
+	Name         string                      `json:"name"`
+	Description  *string                     `json:"description"`
+	Manufacturer *string                     `json:"manufacturer"`
+	Form         string                      `json:"form"`
+	Strength     float32                     `json:"strength"`
+	StrengthUnit shared.DoseUnit             `json:"strength_unit"`
+	PackageSize  float32                     `json:"package_size"`
+	PackageUnit  shared.DoseUnit             `json:"package_unit"`
+	MealRelation shared.MealRelation         `json:"meal_relation"`
+	ExternalCode *string                     `json:"external_code"`
+	Barcodes     []string                    `json:"barcodes"`
+	Visibility   shared.MedicationVisibility `json:"visibility"`
+	OwnerID      *uuid.UUID                  `json:"owner_id,omitempty"`
+	Status       shared.MedicationStatus     `json:"status"`
+	SubmittedBy  *uuid.UUID                  `json:"submitted_by"`
+	ArchivedAt   *time.Time                  `json:"archived_at,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+
+	// Deprecated: StrengthMg is the strength in mg, null when it is not a mass; use strength
+	StrengthMg *float32 `json:"strength_mg"`
+	// Deprecated: PillsPerBox equals package_size
+	PillsPerBox float32 `json:"pills_per_box"`
+}
+
+// MedicationBarcodeResponse is the entry a scanned barcode belongs to, with a tracking request
+// prefilled from it; schedules and duration are left for the user to fill in
+type MedicationBarcodeResponse struct {
+	Medication *MedicationResponse         `json:"medication"`
+	Prefill    UserMedicationCreateRequest `json:"prefill"`
+}
+
+type MedicationMergeRequest struct {
+	IntoMedicationID uuid.UUID `json:"into_medication_id" validate:"required"`
+}
+
+// MedicationMergeResponse is the entry a duplicate was merged into, how many trackings moved to it
+// and the milder interactions they now have with the other medications of their profiles
+type MedicationMergeResponse struct {
+	Medication           *MedicationResponse  `json:"medication"`
+	MovedUserMedications int                  `json:"moved_user_medications"`
+	InteractionWarnings  []InteractionWarning `json:"interaction_warnings,omitempty"`
+}
+
+// MedicationSearchQuery filters and pages the approved catalog; empty fields match everything
+type MedicationSearchQuery struct {
+	ViewerID     uuid.UUID
+	Visibility   shared.MedicationVisibility
+	Query        string
+	Forms        []string
+	Manufacturer string
+	StrengthMin  *float64
+	StrengthMax  *float64
+	MealRelation shared.MealRelation
+	Sort         string
+	Cursor       string
+	Limit        int
+}
+
+type MedicationListResponse struct {
+	Items      []*MedicationResponse `json:"items"`
+	Total      int                   `json:"total"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+// MedicationDuplicateResponse is a probable duplicate: same form and strength, and an equal
+// (same_name) or similar normalised name. Score is the name similarity from 0 to 1.
+type MedicationDuplicateResponse struct {
+	Medication *MedicationResponse `json:"medication"`
+	Score      float64             `json:"score"`
+	SameName   bool                `json:"same_name"`
 }
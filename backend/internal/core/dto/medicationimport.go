@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+
+	"github.com/google/uuid"
+)
+
+type MedicationImportRowResult struct {
+	Row          int                 `json:"row"`
+	Name         string              `json:"name,omitempty"`
+	ExternalCode *string             `json:"external_code,omitempty"`
+	Action       shared.ImportAction `json:"action"`
+	MedicationID *uuid.UUID          `json:"medication_id,omitempty"`
+	Errors       []string            `json:"errors,omitempty"`
+}
+
+type MedicationImportReport struct {
+	DryRun    bool                         `json:"dry_run"`
+	Total     int                          `json:"total"`
+	Created   int                          `json:"created"`
+	Updated   int                          `json:"updated"`
+	Unchanged int                          `json:"unchanged"`
+	Failed    int                          `json:"failed"`
+	Rows      []*MedicationImportRowResult `json:"rows"`
+}
@@ -16,6 +16,9 @@ type MedicationLogResponse struct {
 	UserMedicationID uuid.UUID       `json:"user_medication_id"`
 	TimeSlot         shared.TimeSlot `json:"time_slot"`
 	PlannedDose      float64         `json:"planned_dose"`
+	DoseUnit         shared.DoseUnit `json:"dose_unit"`
 	Taken            bool            `json:"taken"`
 	Timestamp        time.Time       `json:"timestamp"`
+	TakenBy          *uuid.UUID      `json:"taken_by,omitempty"`
+	TakenAt          *time.Time      `json:"taken_at,omitempty"`
 }
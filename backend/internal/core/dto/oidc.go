@@ -0,0 +1,11 @@
+package dto
+
+type OIDCProviderResponse struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"display_name"`
+	AuthorizeURL string `json:"authorize_url"`
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
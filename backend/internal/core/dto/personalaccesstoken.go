@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PersonalAccessTokenCreateRequest struct {
+	Name          string   `json:"name"                      validate:"required,max=100"`
+	Scopes        []string `json:"scopes"                    validate:"required,min=1"`
+	ExpiresInDays *int     `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  *string    `json:"last_used_ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PersonalAccessTokenCreatedResponse carries the raw token, which is only ever shown once
+type PersonalAccessTokenCreatedResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileSettings controls when doses are scheduled. Omitted fields keep their current value;
+// slot_times may list only the slots to change, e.g. {"morning": "07:30"}.
+type ProfileSettings struct {
+	Timezone  *string                    `json:"timezone,omitempty"   validate:"omitempty,timezone"`
+	Locale    *string                    `json:"locale,omitempty"     validate:"omitempty,bcp47_language_tag"`
+	SlotTimes map[shared.TimeSlot]string `json:"slot_times,omitempty" validate:"omitempty,dive,keys,oneof=morning noon evening night,endkeys,datetime=15:04"`
+}
+
+type ProfileCreateRequest struct {
+	Name string             `json:"name" validate:"required,max=100"`
+	Kind shared.ProfileKind `json:"kind" validate:"required,oneof=self child pet other"`
+	ProfileSettings
+}
+
+type ProfileUpdateRequest struct {
+	Name *string             `json:"name,omitempty" validate:"omitempty,max=100"`
+	Kind *shared.ProfileKind `json:"kind,omitempty" validate:"omitempty,oneof=self child pet other"`
+	ProfileSettings
+}
+
+type ProfileResponse struct {
+	ID        uuid.UUID                  `json:"id"`
+	UserID    uuid.UUID                  `json:"user_id"`
+	Name      string                     `json:"name"`
+	Kind      shared.ProfileKind         `json:"kind"`
+	IsDefault bool                       `json:"is_default"`
+	Timezone  string                     `json:"timezone"`
+	Locale    string                     `json:"locale"`
+	SlotTimes map[shared.TimeSlot]string `json:"slot_times"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorDisableRequest takes either a current TOTP code or a recovery code in Code
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code"     validate:"required"`
+}
+
+// TwoFactorVerifyRequest completes a login; exactly one of Code or RecoveryCode is expected
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string  `json:"challenge_token"         validate:"required"`
+	Code           string  `json:"code,omitempty"          validate:"omitempty,len=6,numeric"`
+	RecoveryCode   string  `json:"recovery_code,omitempty" validate:"omitempty"`
+	DeviceName     *string `json:"device_name,omitempty"   validate:"omitempty,max=100"`
+}
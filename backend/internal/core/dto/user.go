@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"backend/internal/core/shared"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,8 +12,26 @@ type UserCreateRequest struct {
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password"     validate:"required,min=8"`
+}
+
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewEmail        string `json:"new_email"        validate:"required,email"`
+}
+
+type UpdateRoleRequest struct {
+	Role shared.Role `json:"role" validate:"required,oneof=user pharmacist admin"`
+}
+
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               uuid.UUID   `json:"id"`
+	Email            string      `json:"email"`
+	Role             shared.Role `json:"role"`
+	EmailVerified    bool        `json:"email_verified"`
+	VerifiedAt       *time.Time  `json:"verified_at"`
+	TwoFactorEnabled bool        `json:"two_factor_enabled"`
+	CreatedAt        time.Time   `json:"created_at"`
 }
@@ -10,9 +10,12 @@ import (
 type IntakeSchedule struct {
 	TimeSlot   shared.TimeSlot `json:"time_slot"   validate:"required,oneof=morning noon evening night"`
 	DoseAmount float64         `json:"dose_amount" validate:"required,gt=0"`
+	// DoseUnit defaults to the medication's package unit; strength units are converted through its strength
+	DoseUnit shared.DoseUnit `json:"dose_unit,omitempty" validate:"omitempty,oneof=mg mcg g iu ml drops puffs units"`
 }
 
 type UserMedicationCreateRequest struct {
+	ProfileID    *uuid.UUID       `json:"profile_id,omitempty"`
 	MedicationID uuid.UUID        `json:"medication_id" validate:"required"`
 	BoxesOwned   int              `json:"boxes_owned"   validate:"required,min=1"`
 	Schedules    []IntakeSchedule `json:"schedules"     validate:"required,min=1,dive"`
@@ -28,6 +31,7 @@ type UserMedicationUpdateRequest struct {
 type UserMedicationResponse struct {
 	ID           uuid.UUID        `json:"id"`
 	UserID       uuid.UUID        `json:"user_id"`
+	ProfileID    uuid.UUID        `json:"profile_id"`
 	MedicationID uuid.UUID        `json:"medication_id"`
 	BoxesOwned   int              `json:"boxes_owned"`
 	Schedules    []IntakeSchedule `json:"schedules"`
@@ -35,17 +39,27 @@ type UserMedicationResponse struct {
 	StartAt      time.Time        `json:"start_at"`
 	Active       bool             `json:"active"`
 	CreatedAt    time.Time        `json:"created_at"`
+
+	// InteractionWarnings is only set when tracking is started
+	InteractionWarnings []InteractionWarning `json:"interaction_warnings,omitempty"`
 }
 
+// UserMedicationStatsResponse reports stock in the medication's package unit
 type UserMedicationStatsResponse struct {
-	TotalPills             int       `json:"total_pills"`
-	UsedPills              float64   `json:"used_pills"`
-	RemainingPills         float64   `json:"remaining_pills"`
-	DailyConsumption       float64   `json:"daily_consumption"`
-	EstimatedDaysRemaining int       `json:"estimated_days_remaining"`
-	EstimatedEndDate       time.Time `json:"estimated_end_date"`
-	PlannedDurationDays    int       `json:"planned_duration_days"`
-	DaysElapsed            int       `json:"days_elapsed"`
-	PlannedDaysRemaining   int       `json:"planned_days_remaining"`
-	WarningLevel           string    `json:"warning_level"` // "normal", "warning", "critical"
+	Unit                   shared.DoseUnit `json:"unit"`
+	TotalQuantity          float64         `json:"total_quantity"`
+	UsedQuantity           float64         `json:"used_quantity"`
+	RemainingQuantity      float64         `json:"remaining_quantity"`
+	DailyConsumption       float64         `json:"daily_consumption"`
+	EstimatedDaysRemaining int             `json:"estimated_days_remaining"`
+	EstimatedEndDate       time.Time       `json:"estimated_end_date"`
+	PlannedDurationDays    int             `json:"planned_duration_days"`
+	DaysElapsed            int             `json:"days_elapsed"`
+	PlannedDaysRemaining   int             `json:"planned_days_remaining"`
+	WarningLevel           string          `json:"warning_level"` // "normal", "warning", "critical"
+
+	// Deprecated: the *_pills fields repeat the quantities for older clients
+	TotalPills     float64 `json:"total_pills"`
+	UsedPills      float64 `json:"used_pills"`
+	RemainingPills float64 `json:"remaining_pills"`
 }
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountDeletionRequest struct {
+	ID           uuid.UUID  `db:"id"`
+	UserID       uuid.UUID  `db:"user_id"`
+	RequestedIP  *string    `db:"requested_ip"`
+	RequestedAt  time.Time  `db:"requested_at"`
+	ScheduledFor time.Time  `db:"scheduled_for"`
+	CancelledAt  *time.Time `db:"cancelled_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+
+	// AttachmentFiles holds the storage keys of the attachments of the user's private medications,
+	// collected by CompleteDue because the rows are gone once the user is deleted
+	AttachmentFiles []string `db:"-"`
+}
+
+// Status derives the workflow state of the request
+func (r *AccountDeletionRequest) Status() string {
+	switch {
+	case r.CompletedAt != nil:
+		return "completed"
+	case r.CancelledAt != nil:
+		return "cancelled"
+	default:
+		return "pending"
+	}
+}
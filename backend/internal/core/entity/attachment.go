@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MedicationAttachment is a photo or leaflet of a catalog entry; the file is kept in storage under StorageKey
+type MedicationAttachment struct {
+	ID           uuid.UUID             `db:"id"`
+	MedicationID uuid.UUID             `db:"medication_id"`
+	Kind         shared.AttachmentKind `db:"kind"`
+	FileName     string                `db:"file_name"`
+	ContentType  string                `db:"content_type"`
+	SizeBytes    int64                 `db:"size_bytes"`
+	StorageKey   string                `db:"storage_key"`
+	ThumbnailKey *string               `db:"thumbnail_key"`
+	UploadedBy   *uuid.UUID            `db:"uploaded_by"`
+	CreatedAt    time.Time             `db:"created_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent records one mutation. Before, After and Changes hold JSON documents;
+// Before is empty for creates and After for deletes.
+type AuditEvent struct {
+	ID            uuid.UUID          `db:"id"`
+	OccurredAt    time.Time          `db:"occurred_at"`
+	ActorID       *uuid.UUID         `db:"actor_id"`
+	SubjectUserID *uuid.UUID         `db:"subject_user_id"`
+	SessionID     *uuid.UUID         `db:"session_id"`
+	TokenID       *uuid.UUID         `db:"token_id"`
+	IPAddress     *string            `db:"ip_address"`
+	UserAgent     *string            `db:"user_agent"`
+	Action        shared.AuditAction `db:"action"`
+	EntityType    shared.AuditEntity `db:"entity_type"`
+	EntityID      uuid.UUID          `db:"entity_id"`
+	Before        []byte             `db:"before"`
+	After         []byte             `db:"after"`
+	Changes       []byte             `db:"changes"`
+}
+
+// AuditEventFilter narrows an audit query; zero values match everything.
+// UserID matches events where the user is either the actor or the subject.
+type AuditEventFilter struct {
+	UserID     *uuid.UUID
+	EntityType shared.AuditEntity
+	EntityID   *uuid.UUID
+	Action     shared.AuditAction
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CareGrant struct {
+	ID              uuid.UUID             `db:"id"`
+	PatientID       uuid.UUID             `db:"patient_id"`
+	CaregiverEmail  string                `db:"caregiver_email"`
+	CaregiverID     *uuid.UUID            `db:"caregiver_id"`
+	Permission      shared.CarePermission `db:"permission"`
+	InviteTokenHash string                `db:"invite_token_hash"`
+	InviteExpiresAt time.Time             `db:"invite_expires_at"`
+	AcceptedAt      *time.Time            `db:"accepted_at"`
+	RevokedAt       *time.Time            `db:"revoked_at"`
+	CreatedAt       time.Time             `db:"created_at"`
+}
+
+// Status reports the lifecycle state of the grant at the given time
+func (g *CareGrant) Status(now time.Time) string {
+	switch {
+	case g.RevokedAt != nil:
+		return "revoked"
+	case g.AcceptedAt != nil:
+		return "active"
+	case !now.Before(g.InviteExpiresAt):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
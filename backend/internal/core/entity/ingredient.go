@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Ingredient struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// MedicationIngredient is one active ingredient of a catalog entry
+type MedicationIngredient struct {
+	MedicationID   uuid.UUID `db:"medication_id"`
+	IngredientID   uuid.UUID `db:"ingredient_id"`
+	IngredientName string    `db:"ingredient_name"`
+	AmountMg       *float32  `db:"amount_mg"`
+}
+
+// Interaction is a known interaction between two ingredients, identified by name when loaded
+type Interaction struct {
+	IngredientA string                     `db:"ingredient_a"`
+	IngredientB string                     `db:"ingredient_b"`
+	Severity    shared.InteractionSeverity `db:"severity"`
+	Description string                     `db:"description"`
+	UpdatedAt   time.Time                  `db:"updated_at"`
+}
+
+// InteractionMatch is an interaction between a medication and another one the patient takes
+type InteractionMatch struct {
+	OtherMedicationID   uuid.UUID                  `db:"other_medication_id"`
+	OtherMedicationName string                     `db:"other_medication_name"`
+	Ingredient          string                     `db:"ingredient"`
+	OtherIngredient     string                     `db:"other_ingredient"`
+	Severity            shared.InteractionSeverity `db:"severity"`
+	Description         string                     `db:"description"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+type LoginThrottle struct {
+	Key           string     `db:"key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
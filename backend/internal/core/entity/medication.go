@@ -5,16 +5,29 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Medication struct {
-	ID           uuid.UUID           `db:"id"`
-	Name         string              `db:"name"`
-	Description  *string             `db:"description"`
-	Manufacturer *string             `db:"manufacturer"`
-	Form         string              `db:"form"`
-	StrengthMg   float32             `db:"strength_mg"`
-	PillsPerBox  int                 `db:"pills_per_box"`
-	MealRelation shared.MealRelation `db:"meal_relation"`
-	CreatedAt    time.Time           `db:"created_at"`
+	ID           uuid.UUID                   `db:"id"`
+	Name         string                      `db:"name"`
+	Description  *string                     `db:"description"`
+	Manufacturer *string                     `db:"manufacturer"`
+	Form         string                      `db:"form"`
+	Strength     float32                     `db:"strength"`
+	StrengthUnit shared.DoseUnit             `db:"strength_unit"`
+	PackageSize  float32                     `db:"package_size"`
+	PackageUnit  shared.DoseUnit             `db:"package_unit"`
+	MealRelation shared.MealRelation         `db:"meal_relation"`
+	ExternalCode *string                     `db:"external_code"`
+	Barcodes     pq.StringArray              `db:"barcodes"`
+	Visibility   shared.MedicationVisibility `db:"visibility"`
+	OwnerID      *uuid.UUID                  `db:"owner_id"`
+	Status       shared.MedicationStatus     `db:"status"`
+	SubmittedBy  *uuid.UUID                  `db:"submitted_by"`
+	ReviewedBy   *uuid.UUID                  `db:"reviewed_by"`
+	ReviewedAt   *time.Time                  `db:"reviewed_at"`
+	ArchivedAt   *time.Time                  `db:"archived_at"`
+	ArchivedBy   *uuid.UUID                  `db:"archived_by"`
+	CreatedAt    time.Time                   `db:"created_at"`
 }
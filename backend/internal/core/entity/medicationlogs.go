@@ -12,6 +12,18 @@ type MedicationLog struct {
 	UserMedicationID uuid.UUID       `db:"user_medication_id"`
 	TimeSlot         shared.TimeSlot `db:"time_slot"`
 	PlannedDose      float64         `db:"planned_dose"`
+	DoseUnit         shared.DoseUnit `db:"dose_unit"`
 	Taken            bool            `db:"taken"`
 	Timestamp        time.Time       `db:"timestamp"`
+	TakenBy          *uuid.UUID      `db:"taken_by"`
+	TakenAt          *time.Time      `db:"taken_at"`
+}
+
+// MedicationLogBatch is what the audit trail records for the logs planned when tracking starts,
+// one event for the whole plan instead of one per dose
+type MedicationLogBatch struct {
+	UserMedicationID uuid.UUID   `db:"user_medication_id"`
+	LogIDs           []uuid.UUID `db:"log_ids"`
+	From             time.Time   `db:"from"`
+	To               time.Time   `db:"to"`
 }
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+
+	"github.com/google/uuid"
+)
+
+// MedicationSearchFilter selects unarchived approved shared entries and ViewerID's private ones; other zero
+// values match everything. Results are ordered by Sort, then by id, and start after After when it is set.
+type MedicationSearchFilter struct {
+	ViewerID     uuid.UUID
+	Visibility   shared.MedicationVisibility
+	Query        string
+	Forms        []string
+	Manufacturer string
+	StrengthMin  *float32
+	StrengthMax  *float32
+	MealRelation shared.MealRelation
+	Sort         shared.MedicationSort
+	Descending   bool
+	After        *MedicationCursor
+	Limit        int
+}
+
+// MedicationCursor is the position of the last row of a page: its sort key in text form and its id
+type MedicationCursor struct {
+	Key string
+	ID  uuid.UUID
+}
+
+// MedicationSearchHit is a catalog entry together with its sort key, which the next cursor is built from
+type MedicationSearchHit struct {
+	Medication
+	SortKey string `db:"sort_key"`
+}
+
+// MedicationDuplicateQuery describes an entry to find probable duplicates of: entries of the same
+// form and strength whose normalised name is equal or similar. Shared entries and OwnerID's private
+// ones are compared; archived entries and ExcludeID are not.
+type MedicationDuplicateQuery struct {
+	Name         string
+	Form         string
+	Strength     float32
+	StrengthUnit shared.DoseUnit
+	OwnerID      *uuid.UUID
+	ExcludeID    *uuid.UUID
+	Limit        int
+}
+
+// MedicationDuplicate is a probable duplicate with the trigram similarity of its normalised name
+type MedicationDuplicate struct {
+	Medication
+	Score    float64 `db:"score"`
+	SameName bool    `db:"same_name"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Profile struct {
+	ID          uuid.UUID          `db:"id"`
+	UserID      uuid.UUID          `db:"user_id"`
+	Name        string             `db:"name"`
+	Kind        shared.ProfileKind `db:"kind"`
+	IsDefault   bool               `db:"is_default"`
+	Timezone    string             `db:"timezone"`
+	Locale      string             `db:"locale"`
+	MorningTime string             `db:"morning_time"`
+	NoonTime    string             `db:"noon_time"`
+	EveningTime string             `db:"evening_time"`
+	NightTime   string             `db:"night_time"`
+	CreatedAt   time.Time          `db:"created_at"`
+}
+
+// SlotTime returns the wall-clock time (HH:MM) the slot falls on for this profile
+func (p *Profile) SlotTime(slot shared.TimeSlot) string {
+	switch slot {
+	case shared.Morning:
+		return p.MorningTime
+	case shared.Noon:
+		return p.NoonTime
+	case shared.Evening:
+		return p.EveningTime
+	case shared.Night:
+		return p.NightTime
+	default:
+		return ""
+	}
+}
+
+// SetSlotTime changes the wall-clock time of one slot; unknown slots are ignored
+func (p *Profile) SetSlotTime(slot shared.TimeSlot, clock string) {
+	switch slot {
+	case shared.Morning:
+		p.MorningTime = clock
+	case shared.Noon:
+		p.NoonTime = clock
+	case shared.Evening:
+		p.EveningTime = clock
+	case shared.Night:
+		p.NightTime = clock
+	}
+}
+
+// ScheduledAt returns the instant the slot falls on for the given calendar day in loc, the profile's
+// timezone loaded once by the caller. Days past the end of the month roll over, so callers can simply add to day.
+func (p *Profile) ScheduledAt(loc *time.Location, year int, month time.Month, day int, slot shared.TimeSlot) (time.Time, error) {
+	clock, err := time.Parse("15:04", p.SlotTime(slot))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Session struct {
+	ID               uuid.UUID  `db:"id"`
+	UserID           uuid.UUID  `db:"user_id"`
+	RefreshTokenHash string     `db:"refresh_token_hash"`
+	DeviceName       *string    `db:"device_name"`
+	UserAgent        *string    `db:"user_agent"`
+	IPAddress        *string    `db:"ip_address"`
+	CreatedAt        time.Time  `db:"created_at"`
+	LastSeenAt       time.Time  `db:"last_seen_at"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	RevokedAt        *time.Time `db:"revoked_at"`
+}
+
+// IsActive reports whether the session can still be used to authenticate
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
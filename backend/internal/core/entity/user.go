@@ -1,14 +1,28 @@
 package entity
 
 import (
+	"backend/internal/core/shared"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID        uuid.UUID `db:"id"`
-	Email     string    `db:"email"`
-	Password  string    `db:"password"`
-	CreatedAt time.Time `db:"created_at"`
+	ID            uuid.UUID   `db:"id"`
+	Email         string      `db:"email"`
+	Password      string      `db:"password"`
+	Role          shared.Role `db:"role"`
+	VerifiedAt    *time.Time  `db:"verified_at"`
+	TOTPSecret    *string     `db:"totp_secret"`
+	TOTPEnabledAt *time.Time  `db:"totp_enabled_at"`
+	TOTPLastStep  *int64      `db:"totp_last_step"`
+	CreatedAt     time.Time   `db:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
 }
@@ -10,11 +10,13 @@ import (
 type IntakeSchedule struct {
 	TimeSlot   shared.TimeSlot `json:"time_slot"`
 	DoseAmount float64         `json:"dose_amount"`
+	DoseUnit   shared.DoseUnit `json:"dose_unit,omitempty"`
 }
 
 type UserMedication struct {
 	ID           uuid.UUID        `db:"id"`
 	UserID       uuid.UUID        `db:"user_id"`
+	ProfileID    uuid.UUID        `db:"profile_id"`
 	MedicationID uuid.UUID        `db:"medication_id"`
 	BoxesOwned   int              `db:"boxes_owned"`
 	Schedules    []IntakeSchedule `db:"schedules"`
@@ -0,0 +1,18 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+)
+
+// AccountDeletionFromEntity converts AccountDeletionRequest entity to AccountDeletionResponse
+func AccountDeletionFromEntity(req *entity.AccountDeletionRequest) *dto.AccountDeletionResponse {
+	return &dto.AccountDeletionResponse{
+		ID:           req.ID,
+		Status:       req.Status(),
+		RequestedAt:  req.RequestedAt,
+		ScheduledFor: req.ScheduledFor,
+		CancelledAt:  req.CancelledAt,
+		CompletedAt:  req.CompletedAt,
+	}
+}
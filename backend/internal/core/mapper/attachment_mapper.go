@@ -0,0 +1,21 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+)
+
+// MedicationAttachmentFromEntity converts MedicationAttachment entity to MedicationAttachmentResponse
+func MedicationAttachmentFromEntity(attachment *entity.MedicationAttachment) *dto.MedicationAttachmentResponse {
+	return &dto.MedicationAttachmentResponse{
+		ID:           attachment.ID,
+		MedicationID: attachment.MedicationID,
+		Kind:         attachment.Kind,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		SizeBytes:    attachment.SizeBytes,
+		HasThumbnail: attachment.ThumbnailKey != nil,
+		UploadedBy:   attachment.UploadedBy,
+		CreatedAt:    attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+	"encoding/json"
+)
+
+// AuditEventFromEntity converts AuditEvent entity to AuditEventResponse
+func AuditEventFromEntity(event *entity.AuditEvent) *dto.AuditEventResponse {
+	return &dto.AuditEventResponse{
+		ID:            event.ID,
+		OccurredAt:    event.OccurredAt,
+		ActorID:       event.ActorID,
+		SubjectUserID: event.SubjectUserID,
+		SessionID:     event.SessionID,
+		TokenID:       event.TokenID,
+		IPAddress:     event.IPAddress,
+		UserAgent:     event.UserAgent,
+		Action:        event.Action,
+		EntityType:    event.EntityType,
+		EntityID:      event.EntityID,
+		Before:        json.RawMessage(event.Before),
+		After:         json.RawMessage(event.After),
+		Changes:       json.RawMessage(event.Changes),
+	}
+}
+
+// AuditEventFilterFromQuery converts AuditEventQuery to the repository filter
+func AuditEventFilterFromQuery(query *dto.AuditEventQuery) entity.AuditEventFilter {
+	return entity.AuditEventFilter{
+		UserID:     query.UserID,
+		EntityType: query.EntityType,
+		EntityID:   query.EntityID,
+		Action:     query.Action,
+		From:       query.From,
+		To:         query.To,
+		Limit:      query.Limit,
+		Offset:     query.Offset,
+	}
+}
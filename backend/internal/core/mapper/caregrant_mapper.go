@@ -0,0 +1,22 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+	"time"
+)
+
+// CareGrantFromEntity converts CareGrant entity to CareGrantResponse
+func CareGrantFromEntity(grant *entity.CareGrant, now time.Time) *dto.CareGrantResponse {
+	return &dto.CareGrantResponse{
+		ID:             grant.ID,
+		PatientID:      grant.PatientID,
+		CaregiverEmail: grant.CaregiverEmail,
+		CaregiverID:    grant.CaregiverID,
+		Permission:     grant.Permission,
+		Status:         grant.Status(now),
+		InviteExpires:  grant.InviteExpiresAt,
+		AcceptedAt:     grant.AcceptedAt,
+		CreatedAt:      grant.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+)
+
+// MedicationIngredientFromEntity converts MedicationIngredient entity to MedicationIngredientResponse
+func MedicationIngredientFromEntity(ingredient *entity.MedicationIngredient) *dto.MedicationIngredientResponse {
+	return &dto.MedicationIngredientResponse{
+		IngredientID: ingredient.IngredientID,
+		Name:         ingredient.IngredientName,
+		AmountMg:     ingredient.AmountMg,
+	}
+}
+
+// InteractionWarningFromEntity converts InteractionMatch entity to InteractionWarning
+func InteractionWarningFromEntity(match *entity.InteractionMatch) dto.InteractionWarning {
+	return dto.InteractionWarning{
+		MedicationID:    match.OtherMedicationID,
+		MedicationName:  match.OtherMedicationName,
+		Ingredient:      match.Ingredient,
+		OtherIngredient: match.OtherIngredient,
+		Severity:        match.Severity,
+		Description:     match.Description,
+	}
+}
@@ -3,9 +3,11 @@ package mapper
 import (
 	"backend/internal/core/dto"
 	"backend/internal/core/entity"
+	"backend/internal/core/shared"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // MedicationToEntity converts MedicationCreateRequest to Medication entity
@@ -16,9 +18,14 @@ func MedicationToEntity(req *dto.MedicationCreateRequest) *entity.Medication {
 		Description:  req.Description,
 		Manufacturer: req.Manufacturer,
 		Form:         req.Form,
-		StrengthMg:   float32(req.StrengthMg),
-		PillsPerBox:  req.PillsPerBox,
+		Strength:     req.Strength,
+		StrengthUnit: req.StrengthUnit,
+		PackageSize:  req.PackageSize,
+		PackageUnit:  req.PackageUnit,
 		MealRelation: req.MealRelation,
+		ExternalCode: req.ExternalCode,
+		Barcodes:     barcodesToEntity(req.Barcodes),
+		Visibility:   req.Visibility,
 		CreatedAt:    time.Now(),
 	}
 }
@@ -31,23 +38,59 @@ func MedicationFromEntity(med *entity.Medication) *dto.MedicationResponse {
 		Description:  med.Description,
 		Manufacturer: med.Manufacturer,
 		Form:         med.Form,
-		StrengthMg:   int(med.StrengthMg),
-		PillsPerBox:  med.PillsPerBox,
+		Strength:     med.Strength,
+		StrengthUnit: med.StrengthUnit,
+		PackageSize:  med.PackageSize,
+		PackageUnit:  med.PackageUnit,
 		MealRelation: med.MealRelation,
+		ExternalCode: med.ExternalCode,
+		Barcodes:     append([]string{}, med.Barcodes...),
+		Visibility:   med.Visibility,
+		OwnerID:      med.OwnerID,
+		Status:       med.Status,
+		SubmittedBy:  med.SubmittedBy,
+		ArchivedAt:   med.ArchivedAt,
 		CreatedAt:    med.CreatedAt,
+		StrengthMg:   strengthMg(med),
+		PillsPerBox:  med.PackageSize,
 	}
 }
 
+// strengthMg is the strength in mg for older clients, nil when the strength is not a mass
+func strengthMg(med *entity.Medication) *float32 {
+	mg, mass := med.StrengthUnit.Milligrams(float64(med.Strength))
+	if !mass {
+		return nil
+	}
+	strength := float32(mg)
+	return &strength
+}
+
 // UpdateMedicationEntity applies MedicationUpdateRequest to existing Medication entity
 func UpdateMedicationEntity(med *entity.Medication, req *dto.MedicationUpdateRequest) {
+	if req.Strength == nil && req.StrengthMg != nil {
+		med.Strength, med.StrengthUnit = *req.StrengthMg, shared.UnitMilligram
+	}
+	if req.PackageSize == nil && req.PillsPerBox != nil {
+		med.PackageSize = *req.PillsPerBox
+	}
 	if req.Name != nil {
 		med.Name = *req.Name
 	}
 	if req.Form != nil {
 		med.Form = *req.Form
 	}
-	if req.StrengthMg != nil {
-		med.StrengthMg = float32(*req.StrengthMg)
+	if req.Strength != nil {
+		med.Strength = *req.Strength
+	}
+	if req.StrengthUnit != nil {
+		med.StrengthUnit = *req.StrengthUnit
+	}
+	if req.PackageSize != nil {
+		med.PackageSize = *req.PackageSize
+	}
+	if req.PackageUnit != nil {
+		med.PackageUnit = *req.PackageUnit
 	}
 	if req.MealRelation != nil {
 		med.MealRelation = *req.MealRelation
@@ -58,4 +101,24 @@ func UpdateMedicationEntity(med *entity.Medication, req *dto.MedicationUpdateReq
 	if req.Description != nil {
 		med.Description = req.Description
 	}
+	if req.ExternalCode != nil {
+		med.ExternalCode = req.ExternalCode
+	}
+	if req.Barcodes != nil {
+		med.Barcodes = barcodesToEntity(*req.Barcodes)
+	}
+}
+
+// barcodesToEntity copies barcodes into a non-nil array, which the NOT NULL column requires
+func barcodesToEntity(barcodes []string) pq.StringArray {
+	return append(pq.StringArray{}, barcodes...)
+}
+
+// MedicationDuplicateFromEntity converts MedicationDuplicate entity to MedicationDuplicateResponse
+func MedicationDuplicateFromEntity(duplicate *entity.MedicationDuplicate) *dto.MedicationDuplicateResponse {
+	return &dto.MedicationDuplicateResponse{
+		Medication: MedicationFromEntity(&duplicate.Medication),
+		Score:      duplicate.Score,
+		SameName:   duplicate.SameName,
+	}
 }
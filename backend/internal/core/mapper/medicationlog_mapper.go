@@ -12,8 +12,11 @@ func MedicationLogFromEntity(log *entity.MedicationLog) *dto.MedicationLogRespon
 		UserMedicationID: log.UserMedicationID,
 		TimeSlot:         log.TimeSlot,
 		PlannedDose:      log.PlannedDose,
+		DoseUnit:         log.DoseUnit,
 		Taken:            log.Taken,
 		Timestamp:        log.Timestamp,
+		TakenBy:          log.TakenBy,
+		TakenAt:          log.TakenAt,
 	}
 }
 
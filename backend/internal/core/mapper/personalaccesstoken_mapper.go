@@ -0,0 +1,20 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+)
+
+// PersonalAccessTokenFromEntity converts PersonalAccessToken entity to PersonalAccessTokenResponse without the secret
+func PersonalAccessTokenFromEntity(token *entity.PersonalAccessToken) *dto.PersonalAccessTokenResponse {
+	return &dto.PersonalAccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		LastUsedIP:  token.LastUsedIP,
+		CreatedAt:   token.CreatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+	"backend/internal/core/shared"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileToEntity converts ProfileCreateRequest to Profile entity; settings come from base
+// (usually the user's default profile) unless the request overrides them
+func ProfileToEntity(userID uuid.UUID, req *dto.ProfileCreateRequest, base *entity.Profile) *entity.Profile {
+	profile := &entity.Profile{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        req.Name,
+		Kind:        req.Kind,
+		IsDefault:   false,
+		Timezone:    base.Timezone,
+		Locale:      base.Locale,
+		MorningTime: base.MorningTime,
+		NoonTime:    base.NoonTime,
+		EveningTime: base.EveningTime,
+		NightTime:   base.NightTime,
+		CreatedAt:   time.Now(),
+	}
+	ApplyProfileSettings(profile, &req.ProfileSettings)
+	return profile
+}
+
+// ProfileFromEntity converts Profile entity to ProfileResponse
+func ProfileFromEntity(profile *entity.Profile) *dto.ProfileResponse {
+	slotTimes := make(map[shared.TimeSlot]string, len(shared.TimeSlots))
+	for _, slot := range shared.TimeSlots {
+		slotTimes[slot] = profile.SlotTime(slot)
+	}
+
+	return &dto.ProfileResponse{
+		ID:        profile.ID,
+		UserID:    profile.UserID,
+		Name:      profile.Name,
+		Kind:      profile.Kind,
+		IsDefault: profile.IsDefault,
+		Timezone:  profile.Timezone,
+		Locale:    profile.Locale,
+		SlotTimes: slotTimes,
+		CreatedAt: profile.CreatedAt,
+	}
+}
+
+// UpdateProfileEntity applies ProfileUpdateRequest to existing Profile entity
+func UpdateProfileEntity(profile *entity.Profile, req *dto.ProfileUpdateRequest) {
+	if req.Name != nil {
+		profile.Name = *req.Name
+	}
+	if req.Kind != nil {
+		profile.Kind = *req.Kind
+	}
+	ApplyProfileSettings(profile, &req.ProfileSettings)
+}
+
+// ApplyProfileSettings copies the settings present in req onto profile
+func ApplyProfileSettings(profile *entity.Profile, req *dto.ProfileSettings) {
+	if req.Timezone != nil {
+		profile.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		profile.Locale = *req.Locale
+	}
+	for slot, clock := range req.SlotTimes {
+		profile.SetSlotTime(slot, clock)
+	}
+}
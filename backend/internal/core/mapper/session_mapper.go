@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/entity"
+
+	"github.com/google/uuid"
+)
+
+// SessionFromEntity converts Session entity to SessionResponse, flagging the caller's own session
+func SessionFromEntity(session *entity.Session, currentSessionID uuid.UUID) *dto.SessionResponse {
+	return &dto.SessionResponse{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		DeviceName: session.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.ID == currentSessionID,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
@@ -3,6 +3,7 @@ package mapper
 import (
 	"backend/internal/core/dto"
 	"backend/internal/core/entity"
+	"backend/internal/core/shared"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,7 @@ func UserToEntity(req *dto.UserCreateRequest, hashedPassword string) *entity.Use
 		ID:        uuid.New(),
 		Email:     req.Email,
 		Password:  hashedPassword,
+		Role:      shared.RoleUser,
 		CreatedAt: time.Now(),
 	}
 }
@@ -21,8 +23,12 @@ func UserToEntity(req *dto.UserCreateRequest, hashedPassword string) *entity.Use
 // UserFromEntity converts User entity to UserResponse
 func UserFromEntity(user *entity.User) *dto.UserResponse {
 	return &dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		Role:             user.Role,
+		EmailVerified:    user.VerifiedAt != nil,
+		VerifiedAt:       user.VerifiedAt,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+		CreatedAt:        user.CreatedAt,
 	}
 }
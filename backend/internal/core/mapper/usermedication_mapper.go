@@ -9,18 +9,20 @@ import (
 )
 
 // UserMedicationToEntity converts UserMedicationCreateRequest to UserMedication entity
-func UserMedicationToEntity(userID uuid.UUID, req *dto.UserMedicationCreateRequest) *entity.UserMedication {
+func UserMedicationToEntity(userID, profileID uuid.UUID, req *dto.UserMedicationCreateRequest) *entity.UserMedication {
 	schedules := make([]entity.IntakeSchedule, len(req.Schedules))
 	for i, s := range req.Schedules {
 		schedules[i] = entity.IntakeSchedule{
 			TimeSlot:   s.TimeSlot,
 			DoseAmount: s.DoseAmount,
+			DoseUnit:   s.DoseUnit,
 		}
 	}
 
 	return &entity.UserMedication{
 		ID:           uuid.New(),
 		UserID:       userID,
+		ProfileID:    profileID,
 		MedicationID: req.MedicationID,
 		BoxesOwned:   req.BoxesOwned,
 		Schedules:    schedules,
@@ -38,12 +40,14 @@ func UserMedicationFromEntity(um *entity.UserMedication) *dto.UserMedicationResp
 		schedules[i] = dto.IntakeSchedule{
 			TimeSlot:   s.TimeSlot,
 			DoseAmount: s.DoseAmount,
+			DoseUnit:   s.DoseUnit,
 		}
 	}
 
 	return &dto.UserMedicationResponse{
 		ID:           um.ID,
 		UserID:       um.UserID,
+		ProfileID:    um.ProfileID,
 		MedicationID: um.MedicationID,
 		BoxesOwned:   um.BoxesOwned,
 		Schedules:    schedules,
@@ -65,6 +69,7 @@ func UpdateUserMedicationEntity(um *entity.UserMedication, req *dto.UserMedicati
 			schedules[i] = entity.IntakeSchedule{
 				TimeSlot:   s.TimeSlot,
 				DoseAmount: s.DoseAmount,
+				DoseUnit:   s.DoseUnit,
 			}
 		}
 		um.Schedules = schedules
@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gtinLength is the length of the longest GTIN; shorter ones are zero-padded to it
+const gtinLength = 14
+
+// NormalizeGTIN checks a GTIN-8, GTIN-12 (UPC-A), GTIN-13 (EAN-13) or GTIN-14 code, ignoring spaces
+// and dashes, and returns it zero-padded to 14 digits so every form of the same code compares equal
+func NormalizeGTIN(code string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, code)
+
+	switch len(digits) {
+	case 8, 12, 13, 14:
+	default:
+		return "", fmt.Errorf("barcode %q must have 8, 12, 13 or 14 digits", code)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("barcode %q must only contain digits", code)
+		}
+	}
+
+	digits = strings.Repeat("0", gtinLength-len(digits)) + digits
+	if checkDigit := gtinCheckDigit(digits[:gtinLength-1]); digits[gtinLength-1] != checkDigit {
+		return "", fmt.Errorf("barcode %q has an invalid check digit, expected %c", code, checkDigit)
+	}
+	return digits, nil
+}
+
+// gtinCheckDigit computes the GS1 mod 10 check digit: digits are weighted 3 and 1 alternately,
+// starting with 3 at the rightmost one
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		weight := 1
+		if (len(digits)-1-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,62 @@
+package shared
+
+import "testing"
+
+func TestNormalizeGTIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr bool
+	}{
+		{name: "EAN-13", code: "4006381333931", want: "04006381333931"},
+		{name: "EAN-13 with spaces", code: "4 006381 333931", want: "04006381333931"},
+		{name: "EAN-13 with wrong check digit", code: "4006381333932", wantErr: true},
+		{name: "UPC-A", code: "036000291452", want: "00036000291452"},
+		{name: "UPC-A with dashes", code: "0-36000-29145-2", want: "00036000291452"},
+		{name: "UPC-A with wrong check digit", code: "036000291453", wantErr: true},
+		{name: "GTIN-8", code: "96385074", want: "00000096385074"},
+		{name: "GTIN-8 with wrong check digit", code: "96385075", wantErr: true},
+		{name: "GTIN-14", code: "10614141000415", want: "10614141000415"},
+		{name: "GTIN-14 with wrong check digit", code: "10614141000416", wantErr: true},
+		{name: "already padded", code: "00036000291452", want: "00036000291452"},
+		{name: "check digit zero", code: "5000000000050", want: "05000000000050"},
+		{name: "unsupported length", code: "1234567", wantErr: true},
+		{name: "GTIN-10", code: "1234567890", wantErr: true},
+		{name: "letters", code: "400638133393A", wantErr: true},
+		{name: "empty", code: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeGTIN(tt.code)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeGTIN(%q) = %s, want an error", tt.code, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeGTIN(%q) error = %v", tt.code, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeGTIN(%q) = %s, want %s", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeGTINEqualForms(t *testing.T) {
+	// The UPC-A code, its EAN-13 form and its GTIN-14 form identify the same product
+	var normalized []string
+	for _, code := range []string{"036000291452", "0036000291452", "00036000291452"} {
+		got, err := NormalizeGTIN(code)
+		if err != nil {
+			t.Fatalf("NormalizeGTIN(%q) error = %v", code, err)
+		}
+		normalized = append(normalized, got)
+	}
+	if normalized[0] != normalized[1] || normalized[1] != normalized[2] {
+		t.Errorf("NormalizeGTIN() = %v, want the same code for every form", normalized)
+	}
+}
@@ -17,3 +17,125 @@ const (
 	Evening TimeSlot = "evening"
 	Night   TimeSlot = "night"
 )
+
+// TimeSlots lists the slots in the order they occur during a day
+var TimeSlots = []TimeSlot{Morning, Noon, Evening, Night}
+
+// Defaults for profiles that have not configured their own schedule settings
+const (
+	DefaultTimezone = "UTC"
+	DefaultLocale   = "en"
+)
+
+// DefaultSlotTimes are the wall-clock times (HH:MM) slots fall on unless a profile overrides them
+var DefaultSlotTimes = map[TimeSlot]string{
+	Morning: "08:00",
+	Noon:    "12:00",
+	Evening: "18:00",
+	Night:   "22:00",
+}
+
+type Role string
+
+const (
+	RoleUser       Role = "user"
+	RolePharmacist Role = "pharmacist"
+	RoleAdmin      Role = "admin"
+)
+
+// CanManageCatalog reports whether the role may write to the shared medication catalog
+func (r Role) CanManageCatalog() bool {
+	return r == RolePharmacist || r == RoleAdmin
+}
+
+type MedicationStatus string
+
+const (
+	MedicationPending  MedicationStatus = "pending"
+	MedicationApproved MedicationStatus = "approved"
+	MedicationRejected MedicationStatus = "rejected"
+)
+
+type CarePermission string
+
+const (
+	CareRead   CarePermission = "read"
+	CareManage CarePermission = "manage"
+)
+
+type ProfileKind string
+
+const (
+	ProfileSelf  ProfileKind = "self"
+	ProfileChild ProfileKind = "child"
+	ProfilePet   ProfileKind = "pet"
+	ProfileOther ProfileKind = "other"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntity names the kind of row an audit event describes
+type AuditEntity string
+
+const (
+	AuditEntityMedication           AuditEntity = "medication"
+	AuditEntityUserMedication       AuditEntity = "user_medication"
+	AuditEntityMedicationLog        AuditEntity = "medication_log"
+	AuditEntityMedicationLogBatch   AuditEntity = "medication_log_batch"
+	AuditEntityMedicationAttachment AuditEntity = "medication_attachment"
+)
+
+// MedicationVisibility tells whether a catalog entry is shared with everyone or private to its owner
+type MedicationVisibility string
+
+const (
+	VisibilityShared  MedicationVisibility = "shared"
+	VisibilityPrivate MedicationVisibility = "private"
+)
+
+// MedicationSort names a field the catalog can be ordered by
+type MedicationSort string
+
+const (
+	SortByRelevance MedicationSort = "relevance"
+	SortByName      MedicationSort = "name"
+	SortByStrength  MedicationSort = "strength_mg"
+	SortByCreatedAt MedicationSort = "created_at"
+)
+
+// ImportAction is what a catalog import did, or would do in a dry run, with one row
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+	ImportError     ImportAction = "error"
+)
+
+type InteractionSeverity string
+
+const (
+	InteractionMinor    InteractionSeverity = "minor"
+	InteractionModerate InteractionSeverity = "moderate"
+	InteractionSevere   InteractionSeverity = "severe"
+)
+
+// Blocks reports whether the interaction is serious enough to refuse starting the medication
+func (s InteractionSeverity) Blocks() bool {
+	return s == InteractionSevere
+}
+
+// AttachmentKind tells what a file attached to a catalog entry shows
+type AttachmentKind string
+
+const (
+	AttachmentImage   AttachmentKind = "image"
+	AttachmentLeaflet AttachmentKind = "leaflet"
+)
@@ -0,0 +1,99 @@
+package shared
+
+import "fmt"
+
+// DoseUnit is the unit a strength, package size or dose is expressed in.
+// UnitCount counts discrete items such as tablets, capsules or suppositories.
+type DoseUnit string
+
+const (
+	UnitMilligram     DoseUnit = "mg"
+	UnitMicrogram     DoseUnit = "mcg"
+	UnitGram          DoseUnit = "g"
+	UnitInternational DoseUnit = "iu"
+	UnitMillilitre    DoseUnit = "ml"
+	UnitDrop          DoseUnit = "drops"
+	UnitPuff          DoseUnit = "puffs"
+	UnitCount         DoseUnit = "units"
+)
+
+// DoseUnits lists every known unit
+var DoseUnits = []DoseUnit{UnitMilligram, UnitMicrogram, UnitGram, UnitInternational, UnitMillilitre, UnitDrop, UnitPuff, UnitCount}
+
+// milligrams per unit of mass
+var massUnits = map[DoseUnit]float64{
+	UnitMicrogram: 0.001,
+	UnitMilligram: 1,
+	UnitGram:      1000,
+}
+
+func (u DoseUnit) IsValid() bool {
+	for _, unit := range DoseUnits {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// Milligrams converts a mass to mg; it reports false for units that are not a mass
+func (u DoseUnit) Milligrams(amount float64) (float64, bool) {
+	factor, mass := massUnits[u]
+	return amount * factor, mass
+}
+
+// IsStrengthUnit reports whether the unit measures an amount of active ingredient
+func (u DoseUnit) IsStrengthUnit() bool {
+	_, mass := massUnits[u]
+	return mass || u == UnitInternational
+}
+
+// IsPackageUnit reports whether a package can be counted in the unit
+func (u DoseUnit) IsPackageUnit() bool {
+	switch u {
+	case UnitCount, UnitMillilitre, UnitDrop, UnitPuff, UnitGram:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultPackageUnit is the package unit assumed for a medication form
+func DefaultPackageUnit(form string) DoseUnit {
+	switch form {
+	case "syrup", "injection":
+		return UnitMillilitre
+	case "drop":
+		return UnitDrop
+	default:
+		return UnitCount
+	}
+}
+
+// ConvertToPackageUnits expresses amount of unit in the package unit of a medication whose
+// strength is given per package unit. Mass units convert into each other; a strength unit
+// converts into package units through the strength, so 250 mg of a 50 mg/ml syrup is 5 ml.
+func ConvertToPackageUnits(amount float64, unit, packageUnit DoseUnit, strength float64, strengthUnit DoseUnit) (float64, error) {
+	if unit == "" || unit == packageUnit {
+		return amount, nil
+	}
+
+	from, fromMass := massUnits[unit]
+	if to, toMass := massUnits[packageUnit]; fromMass && toMass {
+		return amount * from / to, nil
+	}
+
+	if unit.IsStrengthUnit() && strength > 0 {
+		perStrengthUnit := 1.0
+		if unit != strengthUnit {
+			to, toMass := massUnits[strengthUnit]
+			if !fromMass || !toMass {
+				return 0, fmt.Errorf("cannot convert %s to %s", unit, strengthUnit)
+			}
+			perStrengthUnit = from / to
+		}
+		return amount * perStrengthUnit / strength, nil
+	}
+
+	return 0, fmt.Errorf("cannot convert %s to %s", unit, packageUnit)
+}
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertToPackageUnits(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       float64
+		unit         DoseUnit
+		packageUnit  DoseUnit
+		strength     float64
+		strengthUnit DoseUnit
+		want         float64
+		wantErr      bool
+	}{
+		{name: "5 ml of a syrup", amount: 5, unit: UnitMillilitre, packageUnit: UnitMillilitre, strength: 50, strengthUnit: UnitMilligram, want: 5},
+		{name: "no unit means package units", amount: 2, packageUnit: UnitCount, strength: 500, strengthUnit: UnitMilligram, want: 2},
+		{name: "250 mg of a 50 mg/ml syrup", amount: 250, unit: UnitMilligram, packageUnit: UnitMillilitre, strength: 50, strengthUnit: UnitMilligram, want: 5},
+		{name: "1 g of 500 mg tablets", amount: 1, unit: UnitGram, packageUnit: UnitCount, strength: 500, strengthUnit: UnitMilligram, want: 2},
+		{name: "500 mcg of 0.25 mg tablets", amount: 500, unit: UnitMicrogram, packageUnit: UnitCount, strength: 0.25, strengthUnit: UnitMilligram, want: 2},
+		{name: "250 mg of a cream sold by the gram", amount: 250, unit: UnitMilligram, packageUnit: UnitGram, strength: 10, strengthUnit: UnitMilligram, want: 0.25},
+		{name: "2 g of a cream sold by the gram", amount: 2, unit: UnitGram, packageUnit: UnitGram, strength: 10, strengthUnit: UnitMilligram, want: 2},
+		{name: "mg into g packages ignores the strength", amount: 1500, unit: UnitMilligram, packageUnit: UnitGram, want: 1.5},
+		{name: "1000 iu of 500 iu capsules", amount: 1000, unit: UnitInternational, packageUnit: UnitCount, strength: 500, strengthUnit: UnitInternational, want: 2},
+		{name: "iu of a medication dosed in mg", amount: 1000, unit: UnitInternational, packageUnit: UnitCount, strength: 500, strengthUnit: UnitMilligram, wantErr: true},
+		{name: "mg of a medication dosed in iu", amount: 10, unit: UnitMilligram, packageUnit: UnitCount, strength: 400, strengthUnit: UnitInternational, wantErr: true},
+		{name: "mg without a strength", amount: 250, unit: UnitMilligram, packageUnit: UnitMillilitre, wantErr: true},
+		{name: "ml of tablets", amount: 5, unit: UnitMillilitre, packageUnit: UnitCount, strength: 500, strengthUnit: UnitMilligram, wantErr: true},
+		{name: "drops of a syrup", amount: 20, unit: UnitDrop, packageUnit: UnitMillilitre, strength: 50, strengthUnit: UnitMilligram, wantErr: true},
+		{name: "puffs of tablets", amount: 2, unit: UnitPuff, packageUnit: UnitCount, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertToPackageUnits(tt.amount, tt.unit, tt.packageUnit, tt.strength, tt.strengthUnit)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ConvertToPackageUnits() = %g, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertToPackageUnits() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ConvertToPackageUnits() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMilligrams(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		unit     DoseUnit
+		want     float64
+		wantMass bool
+	}{
+		{amount: 0.5, unit: UnitGram, want: 500, wantMass: true},
+		{amount: 250, unit: UnitMilligram, want: 250, wantMass: true},
+		{amount: 100, unit: UnitMicrogram, want: 0.1, wantMass: true},
+		{amount: 400, unit: UnitInternational, wantMass: false},
+		{amount: 5, unit: UnitMillilitre, wantMass: false},
+	}
+
+	for _, tt := range tests {
+		got, mass := tt.unit.Milligrams(tt.amount)
+		if mass != tt.wantMass || mass && math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s.Milligrams(%g) = %g, %v, want %g, %v", tt.unit, tt.amount, got, mass, tt.want, tt.wantMass)
+		}
+	}
+}
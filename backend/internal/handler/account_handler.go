@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"archive/zip"
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// Export godoc
+// @Summary      Download my data
+// @Description  Export every record belonging to the current user as a ZIP archive of JSON files or as a single JSON document
+// @Tags         account
+// @Produce      application/zip
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format query string false "Export format" Enums(zip, json) default(zip)
+// @Success      200 {object} dto.DataExportResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/export [get]
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "zip")
+	if format != "zip" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be zip or json"})
+		return
+	}
+
+	export, err := h.accountService.Export(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileName := fmt.Sprintf("doselog-export-%s", export.ExportedAt.Format("20060102-150405"))
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".json"))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".zip"))
+	c.Status(http.StatusOK)
+
+	if err := writeExportZip(c.Writer, export); err != nil {
+		// Headers are already sent, so the best we can do is log the truncated download
+		log.Printf("failed to write data export for user %s: %v", export.User.ID, err)
+	}
+}
+
+func writeExportZip(w http.ResponseWriter, export *dto.DataExportResponse) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"sessions.json", export.Sessions},
+		{"profiles.json", export.Profiles},
+		{"medications.json", export.Medications},
+		{"user_medications.json", export.UserMedications},
+		{"medication_logs.json", export.MedicationLogs},
+		{"account_deletion_requests.json", export.DeletionRequests},
+		{"care_grants.json", export.CareGrants},
+		{"audit_events.json", export.AuditEvents},
+	}
+
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// RequestDeletion godoc
+// @Summary      Delete my account
+// @Description  Schedule the account and all of its data for deletion after a grace period
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.DeleteAccountRequest true "Current password"
+// @Success      202 {object} dto.AccountDeletionResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/deletion [post]
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(c.Request.Context(), userID.(uuid.UUID), &req, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetDeletion godoc
+// @Summary      Get pending account deletion
+// @Description  Get the pending deletion of the current user's account, if any
+// @Tags         account
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} dto.AccountDeletionResponse
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/deletion [get]
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	deletion, err := h.accountService.GetPendingDeletion(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if deletion == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no pending account deletion"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel account deletion
+// @Description  Cancel a pending account deletion during the grace period
+// @Tags         account
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(c.Request.Context(), userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead leaves room for the multipart framing around the largest accepted file
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+	medicationService *service.MedicationService
+}
+
+func NewAttachmentHandler(attachmentService *service.AttachmentService, medicationService *service.MedicationService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		medicationService: medicationService,
+	}
+}
+
+// Upload godoc
+// @Summary      Upload medication attachment
+// @Description  Attach a photo of the pill or box (JPEG or PNG, a thumbnail is generated) or the package leaflet (PDF) to a catalog entry. The type is detected from the file content. Shared entries can be changed by pharmacists and admins, private ones only by their owner.
+// @Tags         medications
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Param        file formData file true "Image or PDF leaflet"
+// @Success      201 {object} dto.MedicationAttachmentResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("userID")
+	if !service.CanEdit(medication, userID.(uuid.UUID), auth.CurrentRole(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+		return
+	}
+
+	maxSize := service.MaxAttachmentSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize+multipartOverhead))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "attachment is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	// Reading one byte past the limit is enough for the service to refuse the file
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxSize)+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded file"})
+		return
+	}
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), medication.ID, userID.(uuid.UUID), fileHeader.Filename, data)
+	if err != nil {
+		var tooLarge *service.AttachmentTooLargeError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge.Error()})
+			return
+		}
+		var invalid *service.AttachmentValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// List godoc
+// @Summary      List medication attachments
+// @Description  Get the photos and leaflets attached to a catalog entry
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {array} dto.MedicationAttachmentResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.List(c.Request.Context(), medication.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Download godoc
+// @Summary      Download medication attachment
+// @Description  Get the file of an attachment, or with thumbnail=true the JPEG thumbnail of an image
+// @Tags         medications
+// @Produce      image/jpeg,image/png,application/pdf
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Param        attachmentId path string true "Attachment ID"
+// @Param        thumbnail query bool false "Return the thumbnail of an image" default(false)
+// @Success      200 {file} binary
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+	thumbnail, _ := strconv.ParseBool(c.DefaultQuery("thumbnail", "false"))
+
+	attachment, reader, err := h.attachmentService.Open(c.Request.Context(), medication.ID, attachmentID, thumbnail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if attachment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		return
+	}
+	defer reader.Close()
+
+	size := attachment.SizeBytes
+	if thumbnail {
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// Delete godoc
+// @Summary      Delete medication attachment
+// @Description  Remove an attachment and its files. Shared entries can be changed by pharmacists and admins, private ones only by their owner.
+// @Tags         medications
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Param        attachmentId path string true "Attachment ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("userID")
+	if !service.CanEdit(medication, userID.(uuid.UUID), auth.CurrentRole(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return
+	}
+
+	deleted, err := h.attachmentService.Delete(c.Request.Context(), medication.ID, attachmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// visibleMedication loads the medication of the path and answers 404 unless the caller may see it
+func (h *AttachmentHandler) visibleMedication(c *gin.Context) (*dto.MedicationResponse, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return nil, false
+	}
+
+	medication, err := h.medicationService.GetVisible(c.Request.Context(), id, userID.(uuid.UUID), auth.CurrentRole(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if medication == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return nil, false
+	}
+
+	return medication, true
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/shared"
+	"backend/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListMine godoc
+// @Summary      Get my audit history
+// @Description  Get the audit events for the current user's data and for changes the current user made, newest first
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        entity_type query string false "medication, user_medication, medication_log, medication_log_batch or medication_attachment"
+// @Param        entity_id query string false "Entity ID"
+// @Param        action query string false "create, update or delete"
+// @Param        from query string false "Only events at or after this time (RFC 3339)"
+// @Param        to query string false "Only events before this time (RFC 3339)"
+// @Param        limit query int false "Limit" default(50)
+// @Param        offset query int false "Offset" default(0)
+// @Success      200 {array} dto.AuditEventResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/audit-events [get]
+func (h *AuditHandler) ListMine(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	query, ok := parseAuditQuery(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.auditService.ListForUser(c.Request.Context(), userID.(uuid.UUID), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// List godoc
+// @Summary      Get audit events
+// @Description  Get the audit trail of all data mutations, newest first (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id query string false "Only events where this user is the actor or the data owner"
+// @Param        entity_type query string false "medication, user_medication, medication_log, medication_log_batch or medication_attachment"
+// @Param        entity_id query string false "Entity ID"
+// @Param        action query string false "create, update or delete"
+// @Param        from query string false "Only events at or after this time (RFC 3339)"
+// @Param        to query string false "Only events before this time (RFC 3339)"
+// @Param        limit query int false "Limit" default(50)
+// @Param        offset query int false "Offset" default(0)
+// @Success      200 {array} dto.AuditEventResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/audit-events [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	query, ok := parseAuditQuery(c)
+	if !ok {
+		return
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+		query.UserID = &id
+	}
+
+	events, err := h.auditService.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// parseAuditQuery reads the filters shared by the user and admin endpoints
+func parseAuditQuery(c *gin.Context) (*dto.AuditEventQuery, bool) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	query := &dto.AuditEventQuery{
+		EntityType: shared.AuditEntity(c.Query("entity_type")),
+		Action:     shared.AuditAction(c.Query("action")),
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	if raw := c.Query("entity_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity id"})
+			return nil, false
+		}
+		query.EntityID = &id
+	}
+
+	var ok bool
+	if query.From, ok = timeQuery(c, "from"); !ok {
+		return nil, false
+	}
+	if query.To, ok = timeQuery(c, "to"); !ok {
+		return nil, false
+	}
+
+	return query, true
+}
+
+// timeQuery parses an optional RFC 3339 query parameter
+func timeQuery(c *gin.Context, param string) (*time.Time, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " time, expected RFC 3339"})
+		return nil, false
+	}
+	return &t, true
+}
@@ -3,26 +3,34 @@ package handler
 import (
 	dto2 "backend/internal/core/dto"
 	service2 "backend/internal/service"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
-	authService *service2.AuthService
-	userService *service2.UserService
+	authService              *service2.AuthService
+	userService              *service2.UserService
+	passwordResetService     *service2.PasswordResetService
+	emailVerificationService *service2.EmailVerificationService
 }
 
-func NewAuthHandler(authService *service2.AuthService, userService *service2.UserService) *AuthHandler {
+func NewAuthHandler(authService *service2.AuthService, userService *service2.UserService, passwordResetService *service2.PasswordResetService, emailVerificationService *service2.EmailVerificationService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		userService: userService,
+		authService:              authService,
+		userService:              userService,
+		passwordResetService:     passwordResetService,
+		emailVerificationService: emailVerificationService,
 	}
 }
 
 // Register godoc
 // @Summary      Register a new user
-// @Description  Create a new user account
+// @Description  Create a new user account and email a verification link
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -44,12 +52,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// The account exists at this point; a mail failure can be recovered through resend-verification
+	if err := h.emailVerificationService.SendVerification(c.Request.Context(), user.ID); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, user)
 }
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user and return JWT token
+// @Description  Authenticate user and return JWT token, or a challenge token when two-factor authentication is enabled
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -57,6 +70,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success      200 {object} dto.LoginResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      429 {object} map[string]string
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto2.LoginRequest
@@ -65,7 +79,34 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(c.Request.Context(), &req)
+	response, err := h.authService.Login(c.Request.Context(), &req, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh godoc
+// @Summary      Refresh access token
+// @Description  Exchange a refresh token for a new access token; the refresh token is rotated
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.RefreshTokenRequest true "Refresh token"
+// @Success      200 {object} dto.TokenResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto2.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.Refresh(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -73,3 +114,193 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// Logout godoc
+// @Summary      User logout
+// @Description  Revoke the session belonging to the refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.LogoutRequest true "Refresh token"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto2.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// ForgotPassword godoc
+// @Summary      Request password reset
+// @Description  Email a single-use password reset link if an account exists for the address
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ForgotPasswordRequest true "Account email"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto2.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password using a reset token; all sessions are logged out and all personal access tokens revoked
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Router       /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto2.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Confirm an email address using the signed link sent after registration
+// @Tags         auth
+// @Produce      json
+// @Param        token query string true "Verification token"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Router       /auth/verify-email [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.emailVerificationService.Verify(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email address verified"})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification link if the address belongs to an unverified account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ResendVerificationRequest true "Account email"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto2.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.emailVerificationService.Resend(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "if the email is registered and unverified, a verification link has been sent"})
+}
+
+// VerifyTwoFactor godoc
+// @Summary      Complete two-factor login
+// @Description  Exchange a login challenge token and a TOTP or recovery code for a JWT token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.TwoFactorVerifyRequest true "Challenge token and second factor"
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      429 {object} map[string]string
+// @Router       /auth/2fa/verify [post]
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req dto2.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.VerifyTwoFactor(c.Request.Context(), &req, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlockAccount godoc
+// @Summary      Unlock account
+// @Description  Lift a temporary login lockout using the link sent by email
+// @Tags         auth
+// @Produce      json
+// @Param        token query string true "Unlock token"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Router       /auth/unlock [get]
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account unlocked"})
+}
+
+// respondLoginError answers lockouts with 429 and Retry-After, and everything else with 401
+func respondLoginError(c *gin.Context, err error) {
+	var tooMany *service2.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
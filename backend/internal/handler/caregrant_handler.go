@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CareGrantHandler struct {
+	careGrantService *service.CareGrantService
+}
+
+func NewCareGrantHandler(careGrantService *service.CareGrantService) *CareGrantHandler {
+	return &CareGrantHandler{
+		careGrantService: careGrantService,
+	}
+}
+
+// Invite godoc
+// @Summary      Invite a caregiver
+// @Description  Invite someone by email to view (read) or also record intake for (manage) the current user's medications
+// @Tags         caregivers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.CareGrantInviteRequest true "Caregiver email and permission"
+// @Success      201 {object} dto.CareGrantResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/caregivers [post]
+func (h *CareGrantHandler) Invite(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.CareGrantInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grant, err := h.careGrantService.Invite(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// ListCaregivers godoc
+// @Summary      List caregivers
+// @Description  Get the pending and active caregiver grants issued by the current user
+// @Tags         caregivers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} dto.CareGrantResponse
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/caregivers [get]
+func (h *CareGrantHandler) ListCaregivers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grants, err := h.careGrantService.ListGranted(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// ListPatients godoc
+// @Summary      List patients
+// @Description  Get the users who granted the current user caregiver access
+// @Tags         caregivers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} dto.CareGrantResponse
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/patients [get]
+func (h *CareGrantHandler) ListPatients(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grants, err := h.careGrantService.ListReceived(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// Accept godoc
+// @Summary      Accept caregiver invite
+// @Description  Accept an invite sent to the current user's email address
+// @Tags         caregivers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.CareGrantAcceptRequest true "Invite token"
+// @Success      200 {object} dto.CareGrantResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /care-grants/accept [post]
+func (h *CareGrantHandler) Accept(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.CareGrantAcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grant, err := h.careGrantService.Accept(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// Revoke godoc
+// @Summary      Revoke caregiver access
+// @Description  End a grant; the patient can revoke a caregiver and a caregiver can step down
+// @Tags         caregivers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Care grant ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /care-grants/{id} [delete]
+func (h *CareGrantHandler) Revoke(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid care grant id"})
+		return
+	}
+
+	if err := h.careGrantService.Revoke(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "care grant revoked"})
+}
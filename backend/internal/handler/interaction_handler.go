@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxInteractionImportFileSize is larger than maxImportFileSize: reference interaction tables are large
+const maxInteractionImportFileSize = 50 << 20
+
+type InteractionHandler struct {
+	interactionService *service.InteractionService
+	medicationService  *service.MedicationService
+}
+
+func NewInteractionHandler(interactionService *service.InteractionService, medicationService *service.MedicationService) *InteractionHandler {
+	return &InteractionHandler{
+		interactionService: interactionService,
+		medicationService:  medicationService,
+	}
+}
+
+// ListIngredients godoc
+// @Summary      Get medication ingredients
+// @Description  Get the active ingredients of a catalog entry
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {array} dto.MedicationIngredientResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id}/ingredients [get]
+func (h *InteractionHandler) ListIngredients(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+
+	ingredients, err := h.interactionService.ListIngredients(c.Request.Context(), medication.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ingredients)
+}
+
+// SetIngredients godoc
+// @Summary      Set medication ingredients
+// @Description  Replace the active ingredients of a catalog entry; unknown ingredients are created. Shared entries can be changed by pharmacists and admins, private ones only by their owner.
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Param        request body dto.MedicationIngredientsRequest true "Ingredients"
+// @Success      200 {array} dto.MedicationIngredientResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /medications/{id}/ingredients [put]
+func (h *InteractionHandler) SetIngredients(c *gin.Context) {
+	medication, ok := h.visibleMedication(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("userID")
+	if !service.CanEdit(medication, userID.(uuid.UUID), auth.CurrentRole(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+		return
+	}
+
+	var req dto.MedicationIngredientsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ingredients, err := h.interactionService.SetIngredients(c.Request.Context(), medication.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ingredients)
+}
+
+// Import godoc
+// @Summary      Import drug interactions
+// @Description  Load a reference interaction table from CSV with the header ingredient_a, ingredient_b, severity (minor, moderate or severe), description. Existing pairs are replaced. Send the file as multipart field "file" or as the raw request body (admin only).
+// @Tags         admin
+// @Accept       multipart/form-data,text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file false "CSV file"
+// @Param        dry_run query bool false "Only validate the rows" default(false)
+// @Success      200 {object} dto.InteractionImportReport
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Router       /admin/interactions/import [post]
+func (h *InteractionHandler) Import(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInteractionImportFileSize)
+
+	var body io.Reader = c.Request.Body
+	if contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); contentType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded file"})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := h.interactionService.ImportInteractions(c.Request.Context(), body, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "import file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// visibleMedication loads the medication of the path and answers 404 unless the caller may see it
+func (h *InteractionHandler) visibleMedication(c *gin.Context) (*dto.MedicationResponse, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return nil, false
+	}
+
+	medication, err := h.medicationService.GetVisible(c.Request.Context(), id, userID.(uuid.UUID), auth.CurrentRole(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if medication == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return nil, false
+	}
+
+	return medication, true
+}
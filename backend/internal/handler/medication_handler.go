@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"backend/internal/auth"
 	"backend/internal/core/dto"
+	"backend/internal/core/shared"
 	"backend/internal/service"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,17 +16,19 @@ import (
 
 type MedicationHandler struct {
 	medicationService *service.MedicationService
+	attachmentService *service.AttachmentService
 }
 
-func NewMedicationHandler(medicationService *service.MedicationService) *MedicationHandler {
+func NewMedicationHandler(medicationService *service.MedicationService, attachmentService *service.AttachmentService) *MedicationHandler {
 	return &MedicationHandler{
 		medicationService: medicationService,
+		attachmentService: attachmentService,
 	}
 }
 
 // Create godoc
 // @Summary      Create medication
-// @Description  Add a new medication to the system
+// @Description  Add a new medication to the catalog. Shared submissions from plain users are stored as pending until reviewed; private entries are only visible to their creator and need no review. Entries of the same form and strength with an equal or similar normalised name are refused with 409 and listed as duplicates unless allow_duplicates is set. An external code or barcode another entry of the same catalog already carries is refused with 409 as well.
 // @Tags         medications
 // @Accept       json
 // @Produce      json
@@ -31,18 +37,35 @@ func NewMedicationHandler(medicationService *service.MedicationService) *Medicat
 // @Success      201 {object} dto.MedicationResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]interface{}
 // @Failure      500 {object} map[string]string
 // @Router       /medications [post]
 func (h *MedicationHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	var req dto.MedicationCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	medication, err := h.medicationService.Create(c.Request.Context(), &req)
+	medication, err := h.medicationService.Create(c.Request.Context(), userID.(uuid.UUID), auth.CurrentRole(c), &req)
+	var duplicate *service.DuplicateMedicationError
+	if errors.As(err, &duplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": duplicate.Error(), "duplicates": duplicate.Duplicates})
+		return
+	}
+	var conflict *service.MedicationConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -51,7 +74,7 @@ func (h *MedicationHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary      Update medication
-// @Description  Update medication details
+// @Description  Update medication details. Shared entries can be changed by pharmacists and admins, private ones only by their owner. An external code or barcode another entry of the same catalog already carries is refused with 409.
 // @Tags         medications
 // @Accept       json
 // @Produce      json
@@ -61,12 +84,14 @@ func (h *MedicationHandler) Create(c *gin.Context) {
 // @Success      200 {object} dto.MedicationResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /medications/{id} [put]
 func (h *MedicationHandler) Update(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+	id, ok := h.editableMedication(c)
+	if !ok {
 		return
 	}
 
@@ -77,10 +102,24 @@ func (h *MedicationHandler) Update(c *gin.Context) {
 	}
 
 	medication, err := h.medicationService.Update(c.Request.Context(), id, &req)
+	var invalid *service.MedicationValidationError
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+		return
+	}
+	var conflict *service.MedicationConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if medication == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return
+	}
 
 	c.JSON(http.StatusOK, medication)
 }
@@ -96,27 +135,158 @@ func (h *MedicationHandler) Update(c *gin.Context) {
 // @Success      200 {object} dto.MedicationResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /medications/{id} [get]
 func (h *MedicationHandler) GetByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
 		return
 	}
 
-	medication, err := h.medicationService.GetByID(c.Request.Context(), id)
+	medication, err := h.medicationService.GetVisible(c.Request.Context(), id, userID.(uuid.UUID), auth.CurrentRole(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if medication == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return
+	}
 
 	c.JSON(http.StatusOK, medication)
 }
 
+// GetByBarcode godoc
+// @Summary      Get medication by barcode
+// @Description  Find the catalog entry a scanned GTIN/EAN box barcode belongs to, preferring the caller's private entry over a shared one, together with a user medication request prefilled from it. GTIN-8, UPC-A, EAN-13 and GTIN-14 codes are accepted.
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code path string true "GTIN/EAN barcode"
+// @Success      200 {object} dto.MedicationBarcodeResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications/by-barcode/{code} [get]
+func (h *MedicationHandler) GetByBarcode(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	code, err := shared.NormalizeGTIN(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.medicationService.GetByBarcode(c.Request.Context(), code, userID.(uuid.UUID), auth.CurrentRole(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no medication found with this barcode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // List godoc
-// @Summary      List medications
-// @Description  Get paginated list of medications
+// @Summary      Search medications
+// @Description  Search the approved shared catalog and the caller's private medications with fuzzy, case-insensitive name matching and filters. Pages are fetched by passing next_cursor back as cursor with the same q, filters and sort.
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q query string false "Name search, tolerant of typos"
+// @Param        form query string false "Comma-separated forms (tablet, capsule, syrup, drop, injection)"
+// @Param        manufacturer query string false "Manufacturer name contains (case-insensitive)"
+// @Param        strength_min query number false "Minimum strength in mg; mcg and g strengths are converted, IU strengths never match"
+// @Param        strength_max query number false "Maximum strength in mg"
+// @Param        meal_relation query string false "before_meal, after_meal, with_meal or irrelevant"
+// @Param        visibility query string false "shared or private to search only one of them"
+// @Param        sort query string false "relevance, name, strength_mg or created_at; prefix with - for descending. Defaults to relevance with q, else -created_at"
+// @Param        cursor query string false "next_cursor of the previous page"
+// @Param        limit query int false "Page size (max 100)" default(20)
+// @Success      200 {object} dto.MedicationListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /medications [get]
+func (h *MedicationHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	query := &dto.MedicationSearchQuery{
+		ViewerID:     userID.(uuid.UUID),
+		Visibility:   shared.MedicationVisibility(c.Query("visibility")),
+		Query:        c.Query("q"),
+		Manufacturer: c.Query("manufacturer"),
+		MealRelation: shared.MealRelation(c.Query("meal_relation")),
+		Sort:         c.Query("sort"),
+		Cursor:       c.Query("cursor"),
+		Limit:        limit,
+	}
+	if raw := c.Query("form"); raw != "" {
+		for _, form := range strings.Split(raw, ",") {
+			if form = strings.TrimSpace(form); form != "" {
+				query.Forms = append(query.Forms, form)
+			}
+		}
+	}
+
+	var ok bool
+	if query.StrengthMin, ok = floatQuery(c, "strength_min"); !ok {
+		return
+	}
+	if query.StrengthMax, ok = floatQuery(c, "strength_max"); !ok {
+		return
+	}
+
+	medications, err := h.medicationService.Search(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, medications)
+}
+
+// floatQuery parses an optional numeric query parameter
+func floatQuery(c *gin.Context, param string) (*float64, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+		return nil, false
+	}
+	return &value, true
+}
+
+// ListPending godoc
+// @Summary      List pending medications
+// @Description  Get catalog submissions waiting for review (pharmacist or admin only)
 // @Tags         medications
 // @Accept       json
 // @Produce      json
@@ -125,13 +295,14 @@ func (h *MedicationHandler) GetByID(c *gin.Context) {
 // @Param        offset query int false "Offset" default(0)
 // @Success      200 {array} dto.MedicationResponse
 // @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
 // @Failure      500 {object} map[string]string
-// @Router       /medications [get]
-func (h *MedicationHandler) List(c *gin.Context) {
+// @Router       /medications/pending [get]
+func (h *MedicationHandler) ListPending(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	medications, err := h.medicationService.List(c.Request.Context(), limit, offset)
+	medications, err := h.medicationService.ListPending(c.Request.Context(), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -139,3 +310,271 @@ func (h *MedicationHandler) List(c *gin.Context) {
 
 	c.JSON(http.StatusOK, medications)
 }
+
+// Approve godoc
+// @Summary      Approve medication
+// @Description  Approve a pending catalog submission (pharmacist or admin only)
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {object} dto.MedicationResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Router       /medications/{id}/approve [post]
+func (h *MedicationHandler) Approve(c *gin.Context) {
+	h.review(c, shared.MedicationApproved)
+}
+
+// Reject godoc
+// @Summary      Reject medication
+// @Description  Reject a pending catalog submission (pharmacist or admin only)
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {object} dto.MedicationResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Router       /medications/{id}/reject [post]
+func (h *MedicationHandler) Reject(c *gin.Context) {
+	h.review(c, shared.MedicationRejected)
+}
+
+func (h *MedicationHandler) review(c *gin.Context, status shared.MedicationStatus) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return
+	}
+
+	medication, err := h.medicationService.Review(c.Request.Context(), id, userID.(uuid.UUID), status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// Archive godoc
+// @Summary      Archive medication
+// @Description  Hide a catalog entry from search and new trackings; existing trackings keep working. Shared entries can be archived by pharmacists and admins, private ones by their owner.
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {object} dto.MedicationResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /medications/{id}/archive [post]
+func (h *MedicationHandler) Archive(c *gin.Context) {
+	id, ok := h.editableMedication(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	medication, err := h.medicationService.Archive(c.Request.Context(), id, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// Restore godoc
+// @Summary      Restore medication
+// @Description  Bring an archived catalog entry back into search
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {object} dto.MedicationResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /medications/{id}/restore [post]
+func (h *MedicationHandler) Restore(c *gin.Context) {
+	id, ok := h.editableMedication(c)
+	if !ok {
+		return
+	}
+
+	medication, err := h.medicationService.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// Delete godoc
+// @Summary      Delete medication
+// @Description  Delete a catalog entry for good. Refused with 409 while user medications track it; archive it, or have an admin merge it into another entry, instead.
+// @Tags         medications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]interface{}
+// @Failure      500 {object} map[string]string
+// @Router       /medications/{id} [delete]
+func (h *MedicationHandler) Delete(c *gin.Context) {
+	id, ok := h.editableMedication(c)
+	if !ok {
+		return
+	}
+
+	// The rows go with the medication, the stored files are removed once it is deleted
+	files, err := h.attachmentService.StorageKeys(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.medicationService.Delete(c.Request.Context(), id)
+	var inUse *service.MedicationInUseError
+	if errors.As(err, &inUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": inUse.Error(), "references": inUse.References})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.attachmentService.RemoveFiles(c.Request.Context(), files...)
+
+	c.Status(http.StatusNoContent)
+}
+
+// Merge godoc
+// @Summary      Merge medications
+// @Description  Merge a duplicate shared entry into another one: trackings and missing ingredients move to the target and the duplicate is deleted (admin only). Both entries must have the same form, package unit and strength. Moved trackings are checked for interactions with the other medications of their profiles: milder ones are returned as interaction_warnings, a severe one refuses the merge with 409.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID of the medication to merge away"
+// @Param        request body dto.MedicationMergeRequest true "Medication to merge into"
+// @Success      200 {object} dto.MedicationMergeResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      409 {object} map[string]interface{}
+// @Router       /admin/medications/{id}/merge [post]
+func (h *MedicationHandler) Merge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return
+	}
+
+	var req dto.MedicationMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.medicationService.Merge(c.Request.Context(), id, req.IntoMedicationID)
+	var blocked *service.InteractionBlockedError
+	if errors.As(err, &blocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": blocked.Error(), "interactions": blocked.Interactions})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Duplicates godoc
+// @Summary      Find duplicate medications
+// @Description  List probable duplicates of a catalog entry, the candidates to merge it into (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Medication ID"
+// @Success      200 {array} dto.MedicationDuplicateResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Router       /admin/medications/{id}/duplicates [get]
+func (h *MedicationHandler) Duplicates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return
+	}
+
+	duplicates, err := h.medicationService.FindDuplicatesOf(c.Request.Context(), id, userID.(uuid.UUID), auth.CurrentRole(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// editableMedication parses the medication id and answers 404 unless the caller sees the entry
+// and 403 unless they may change it
+func (h *MedicationHandler) editableMedication(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication id"})
+		return uuid.Nil, false
+	}
+
+	medication, err := h.medicationService.GetVisible(c.Request.Context(), id, userID.(uuid.UUID), auth.CurrentRole(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+	if medication == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return uuid.Nil, false
+	}
+	if !service.CanEdit(medication, userID.(uuid.UUID), auth.CurrentRole(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: access denied"})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"backend/internal/service"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limits uploads to the import endpoint
+const maxImportFileSize = 10 << 20
+
+type MedicationImportHandler struct {
+	importService *service.MedicationImportService
+}
+
+func NewMedicationImportHandler(importService *service.MedicationImportService) *MedicationImportHandler {
+	return &MedicationImportHandler{
+		importService: importService,
+	}
+}
+
+// Import godoc
+// @Summary      Import medications
+// @Description  Create or update catalog entries from a CSV (header row with name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes; the units default to mg and the form's usual package unit, several barcodes are separated by semicolons) or a JSON array of medications. Rows are matched by external_code, then by name. Send the file as multipart field "file" or as the raw request body. Each row is reported separately (admin only).
+// @Tags         admin
+// @Accept       multipart/form-data,text/csv,application/json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file false "CSV or JSON file"
+// @Param        format query string false "csv or json; detected from the file name or Content-Type if omitted"
+// @Param        dry_run query bool false "Only report what would be created or updated" default(false)
+// @Success      200 {object} dto.MedicationImportReport
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Router       /admin/medications/import [post]
+func (h *MedicationImportHandler) Import(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	format := strings.ToLower(c.Query("format"))
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	var body io.Reader = c.Request.Body
+	if contentType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read uploaded file"})
+			return
+		}
+		defer file.Close()
+
+		body = file
+		if format == "" {
+			format = importFormatFromName(fileHeader.Filename)
+		}
+	}
+	if format == "" {
+		format = importFormatFromContentType(contentType)
+	}
+
+	report, err := h.importService.Import(c.Request.Context(), format, body, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "import file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func importFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return service.ImportFormatCSV
+	case ".json":
+		return service.ImportFormatJSON
+	default:
+		return ""
+	}
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch contentType {
+	case "text/csv", "application/csv":
+		return service.ImportFormatCSV
+	case "application/json":
+		return service.ImportFormatJSON
+	default:
+		return ""
+	}
+}
@@ -12,23 +12,26 @@ import (
 type MedicationLogHandler struct {
 	medicationLogService  *service.MedicationLogService
 	userMedicationService *service.UserMedicationService
+	policy                *auth.Policy
 }
 
-func NewMedicationLogHandler(medicationLogService *service.MedicationLogService, userMedicationService *service.UserMedicationService) *MedicationLogHandler {
+func NewMedicationLogHandler(medicationLogService *service.MedicationLogService, userMedicationService *service.UserMedicationService, policy *auth.Policy) *MedicationLogHandler {
 	return &MedicationLogHandler{
 		medicationLogService:  medicationLogService,
 		userMedicationService: userMedicationService,
+		policy:                policy,
 	}
 }
 
 // MarkAsTaken godoc
 // @Summary      Mark dose as taken
-// @Description  Mark a medication log as taken
+// @Description  Mark a medication log as taken. Caregivers with manage access may do this for their patients; the acting user is recorded.
 // @Tags         medication-logs
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Medication Log ID"
+// @Param        profile_id query string false "Profile ID the log must belong to"
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
@@ -63,11 +66,12 @@ func (h *MedicationLogHandler) MarkAsTaken(c *gin.Context) {
 		return
 	}
 
-	if !auth.RequireResourceOwnership(c, userMedication.UserID) {
+	if !inProfileScope(c, userMedication.ProfileID) || !h.policy.Authorize(c, userMedication.UserID, auth.ActionManage) {
 		return
 	}
 
-	if err := h.medicationLogService.MarkAsTaken(c.Request.Context(), id); err != nil {
+	userID, _ := c.Get("userID")
+	if err := h.medicationLogService.MarkAsTaken(c.Request.Context(), id, userID.(uuid.UUID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -83,6 +87,7 @@ func (h *MedicationLogHandler) MarkAsTaken(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        user_medication_id path string true "User Medication ID"
+// @Param        profile_id query string false "Profile ID the user medication must belong to"
 // @Success      200 {array} dto.MedicationLogResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
@@ -107,7 +112,7 @@ func (h *MedicationLogHandler) GetByUserMedicationID(c *gin.Context) {
 		return
 	}
 
-	if !auth.RequireResourceOwnership(c, userMedication.UserID) {
+	if !inProfileScope(c, userMedication.ProfileID) || !h.policy.Authorize(c, userMedication.UserID, auth.ActionRead) {
 		return
 	}
 
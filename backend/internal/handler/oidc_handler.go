@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// Providers godoc
+// @Summary      List identity providers
+// @Description  Get the OpenID Connect providers users can sign in with
+// @Tags         auth
+// @Produce      json
+// @Success      200 {array} dto.OIDCProviderResponse
+// @Router       /auth/oidc/providers [get]
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.Providers())
+}
+
+// Authorize godoc
+// @Summary      Start OIDC login
+// @Description  Redirect to the provider's sign-in page (authorization code flow with PKCE). With format=json the URL is returned instead.
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Param        device_name query string false "Name of the device the session is for"
+// @Param        format query string false "Set to json to get the URL instead of a redirect"
+// @Success      200 {object} dto.OIDCAuthorizeResponse
+// @Success      302
+// @Failure      400 {object} map[string]string
+// @Router       /auth/oidc/{provider}/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	var deviceName *string
+	if name := c.Query("device_name"); name != "" {
+		if len(name) > 100 {
+			name = name[:100]
+		}
+		deviceName = &name
+	}
+
+	authURL, err := h.oidcService.Begin(c.Request.Context(), c.Param("provider"), deviceName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, dto.OIDCAuthorizeResponse{AuthorizationURL: authURL})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary      Finish OIDC login
+// @Description  Redirect target of the provider. Links the identity to an account by subject or by verified email (only an account whose own address is verified is linked), creating one if needed, and returns the same tokens as password login.
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Param        code query string true "Authorization code"
+// @Param        state query string true "State"
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		message := providerError
+		if description := c.Query("error_description"); description != "" {
+			message += ": " + description
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	response, err := h.oidcService.Complete(c.Request.Context(), c.Param("provider"), code, state, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService *service.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService *service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// Create godoc
+// @Summary      Create personal access token
+// @Description  Create a named, scoped token for scripts and integrations. The token is only shown in this response.
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.PersonalAccessTokenCreateRequest true "Token name, scopes and optional lifetime"
+// @Success      201 {object} dto.PersonalAccessTokenCreatedResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/tokens [post]
+func (h *PersonalAccessTokenHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.PersonalAccessTokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.tokenService.Create(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// List godoc
+// @Summary      List personal access tokens
+// @Description  Get the current user's unrevoked tokens with their scopes and last use
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} dto.PersonalAccessTokenResponse
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/tokens [get]
+func (h *PersonalAccessTokenHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tokens, err := h.tokenService.List(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Revoke godoc
+// @Summary      Revoke personal access token
+// @Description  Revoke one of the current user's tokens
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Token ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /me/tokens/{id} [delete]
+func (h *PersonalAccessTokenHandler) Revoke(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token id"})
+		return
+	}
+
+	if err := h.tokenService.Revoke(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "token revoked"})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProfileHandler struct {
+	profileService *service.ProfileService
+	policy         *auth.Policy
+}
+
+func NewProfileHandler(profileService *service.ProfileService, policy *auth.Policy) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		policy:         policy,
+	}
+}
+
+// List godoc
+// @Summary      List profiles
+// @Description  Get the profiles of the current user, or of a patient who granted the current user caregiver access
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id query string false "Patient user ID (defaults to the current user)"
+// @Success      200 {array} dto.ProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/profiles [get]
+func (h *ProfileHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	ownerID := userID.(uuid.UUID)
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+		if !h.policy.Authorize(c, id, auth.ActionRead) {
+			return
+		}
+		ownerID = id
+	}
+
+	profiles, err := h.profileService.List(c.Request.Context(), ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// Create godoc
+// @Summary      Create profile
+// @Description  Add a dependent profile (child, pet, ...) to the current account. Timezone, locale and slot times default to those of the default profile.
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body dto.ProfileCreateRequest true "Profile details"
+// @Success      201 {object} dto.ProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/profiles [post]
+func (h *ProfileHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req dto.ProfileCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.profileService.Create(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// Update godoc
+// @Summary      Update profile
+// @Description  Rename a profile, change its kind or its schedule settings (IANA timezone, locale, slot clock times such as {"morning": "07:30"}). New schedules use the updated times.
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Profile ID"
+// @Param        request body dto.ProfileUpdateRequest true "Updated profile details"
+// @Success      200 {object} dto.ProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/profiles/{id} [put]
+func (h *ProfileHandler) Update(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid profile id"})
+		return
+	}
+
+	var req dto.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.profileService.Update(c.Request.Context(), userID.(uuid.UUID), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// Delete godoc
+// @Summary      Delete profile
+// @Description  Delete a dependent profile together with its medication trackings and logs. The default profile cannot be deleted.
+// @Tags         profiles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Profile ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /me/profiles/{id} [delete]
+func (h *ProfileHandler) Delete(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid profile id"})
+		return
+	}
+
+	if err := h.profileService.Delete(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "profile deleted"})
+}
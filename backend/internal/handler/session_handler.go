@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"backend/internal/auth"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+	policy         *auth.Policy
+}
+
+func NewSessionHandler(sessionService *service.SessionService, policy *auth.Policy) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		policy:         policy,
+	}
+}
+
+// List godoc
+// @Summary      List active sessions
+// @Description  Get the devices the current user is logged in on
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} dto.SessionResponse
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/sessions [get]
+func (h *SessionHandler) List(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	sessionID, _ := c.Get("sessionID")
+
+	sessions, err := h.sessionService.ListActive(c.Request.Context(), userID.(uuid.UUID), sessionID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// Revoke godoc
+// @Summary      Revoke a session
+// @Description  Log out a single device of the current user
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Session ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/sessions/{id} [delete]
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	session, err := h.sessionService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	if !h.policy.Authorize(c, session.UserID, auth.ActionOwner) {
+		return
+	}
+
+	if err := h.sessionService.Revoke(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeOthers godoc
+// @Summary      Revoke all other sessions
+// @Description  Log out every device of the current user except the one making the request
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /me/sessions [delete]
+func (h *SessionHandler) RevokeOthers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	sessionID, _ := c.Get("sessionID")
+
+	if err := h.sessionService.RevokeOthers(c.Request.Context(), userID.(uuid.UUID), sessionID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "other sessions revoked"})
+}
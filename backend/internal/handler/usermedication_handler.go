@@ -4,6 +4,7 @@ import (
 	"backend/internal/auth"
 	"backend/internal/core/dto"
 	"backend/internal/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,17 +13,21 @@ import (
 
 type UserMedicationHandler struct {
 	userMedicationService *service.UserMedicationService
+	profileService        *service.ProfileService
+	policy                *auth.Policy
 }
 
-func NewUserMedicationHandler(userMedicationService *service.UserMedicationService) *UserMedicationHandler {
+func NewUserMedicationHandler(userMedicationService *service.UserMedicationService, profileService *service.ProfileService, policy *auth.Policy) *UserMedicationHandler {
 	return &UserMedicationHandler{
 		userMedicationService: userMedicationService,
+		profileService:        profileService,
+		policy:                policy,
 	}
 }
 
 // Create godoc
 // @Summary      Start medication tracking
-// @Description  Create user medication tracking with schedules
+// @Description  Create user medication tracking with schedules for one of the current user's profiles (default profile if profile_id is omitted). Interactions with the profile's active medications are returned as interaction_warnings; a severe interaction refuses the request with 409.
 // @Tags         user-medications
 // @Accept       json
 // @Produce      json
@@ -31,6 +36,7 @@ func NewUserMedicationHandler(userMedicationService *service.UserMedicationServi
 // @Success      201 {object} dto.UserMedicationResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]interface{}
 // @Failure      500 {object} map[string]string
 // @Router       /user-medications [post]
 func (h *UserMedicationHandler) Create(c *gin.Context) {
@@ -47,6 +53,11 @@ func (h *UserMedicationHandler) Create(c *gin.Context) {
 	}
 
 	userMedication, err := h.userMedicationService.Create(c.Request.Context(), userID.(uuid.UUID), &req)
+	var blocked *service.InteractionBlockedError
+	if errors.As(err, &blocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": blocked.Error(), "interactions": blocked.Interactions})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -57,7 +68,7 @@ func (h *UserMedicationHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary      Update medication tracking
-// @Description  Update user medication tracking details
+// @Description  Update user medication tracking details. Setting active back to true checks interactions with the profile's active medications again: they are returned as interaction_warnings, and a severe interaction refuses the request with 409.
 // @Tags         user-medications
 // @Accept       json
 // @Produce      json
@@ -69,6 +80,7 @@ func (h *UserMedicationHandler) Create(c *gin.Context) {
 // @Failure      401 {object} map[string]string
 // @Failure      403 {object} map[string]string
 // @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]interface{}
 // @Failure      500 {object} map[string]string
 // @Router       /user-medications/{id} [put]
 func (h *UserMedicationHandler) Update(c *gin.Context) {
@@ -88,7 +100,7 @@ func (h *UserMedicationHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if !auth.RequireResourceOwnership(c, userMedication.UserID) {
+	if !h.policy.Authorize(c, userMedication.UserID, auth.ActionOwner) {
 		return
 	}
 
@@ -99,6 +111,11 @@ func (h *UserMedicationHandler) Update(c *gin.Context) {
 	}
 
 	updatedUserMedication, err := h.userMedicationService.Update(c.Request.Context(), id, &req)
+	var blocked *service.InteractionBlockedError
+	if errors.As(err, &blocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": blocked.Error(), "interactions": blocked.Interactions})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -107,25 +124,29 @@ func (h *UserMedicationHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedUserMedication)
 }
 
-// GetByUserID godoc
+// GetByProfileID godoc
 // @Summary      Get user medications
-// @Description  Get all medication trackings for current user
+// @Description  Get all medication trackings of a profile: the given profile, else the default profile of user_id (a patient who granted caregiver access), else the current user's default profile
 // @Tags         user-medications
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        profile_id query string false "Profile ID"
+// @Param        user_id query string false "Patient user ID (defaults to the current user)"
 // @Success      200 {array} dto.UserMedicationResponse
+// @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /user-medications [get]
-func (h *UserMedicationHandler) GetByUserID(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+func (h *UserMedicationHandler) GetByProfileID(c *gin.Context) {
+	profileID, ok := h.resolveProfile(c)
+	if !ok {
 		return
 	}
 
-	userMedications, err := h.userMedicationService.GetByUserID(c.Request.Context(), userID.(uuid.UUID))
+	userMedications, err := h.userMedicationService.GetByProfileID(c.Request.Context(), profileID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -134,25 +155,29 @@ func (h *UserMedicationHandler) GetByUserID(c *gin.Context) {
 	c.JSON(http.StatusOK, userMedications)
 }
 
-// GetActiveByUserID godoc
+// GetActiveByProfileID godoc
 // @Summary      Get active user medications
-// @Description  Get active medication trackings for current user
+// @Description  Get active medication trackings of a profile: the given profile, else the default profile of user_id (a patient who granted caregiver access), else the current user's default profile
 // @Tags         user-medications
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        profile_id query string false "Profile ID"
+// @Param        user_id query string false "Patient user ID (defaults to the current user)"
 // @Success      200 {array} dto.UserMedicationResponse
+// @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /user-medications/active [get]
-func (h *UserMedicationHandler) GetActiveByUserID(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+func (h *UserMedicationHandler) GetActiveByProfileID(c *gin.Context) {
+	profileID, ok := h.resolveProfile(c)
+	if !ok {
 		return
 	}
 
-	userMedications, err := h.userMedicationService.GetActiveByUserID(c.Request.Context(), userID.(uuid.UUID))
+	userMedications, err := h.userMedicationService.GetActiveByProfileID(c.Request.Context(), profileID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -163,12 +188,13 @@ func (h *UserMedicationHandler) GetActiveByUserID(c *gin.Context) {
 
 // GetStats godoc
 // @Summary      Get medication statistics
-// @Description  Get detailed statistics about medication usage and remaining pills
+// @Description  Get detailed statistics about medication usage and remaining stock, in the medication's package unit
 // @Tags         user-medications
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "User Medication ID"
+// @Param        profile_id query string false "Profile ID the user medication must belong to"
 // @Success      200 {object} dto.UserMedicationStatsResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
@@ -193,7 +219,7 @@ func (h *UserMedicationHandler) GetStats(c *gin.Context) {
 		return
 	}
 
-	if !auth.RequireResourceOwnership(c, userMedication.UserID) {
+	if !inProfileScope(c, userMedication.ProfileID) || !h.policy.Authorize(c, userMedication.UserID, auth.ActionRead) {
 		return
 	}
 
@@ -205,3 +231,71 @@ func (h *UserMedicationHandler) GetStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// resolveProfile returns the profile whose medications are requested. An explicit profile_id wins;
+// otherwise the default profile of user_id (a caregiver's patient) or of the caller is used.
+func (h *UserMedicationHandler) resolveProfile(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+
+	if raw := c.Query("profile_id"); raw != "" {
+		profileID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid profile id"})
+			return uuid.Nil, false
+		}
+
+		profile, err := h.profileService.GetByID(c.Request.Context(), profileID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return uuid.Nil, false
+		}
+		if profile == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "profile not found"})
+			return uuid.Nil, false
+		}
+		if !h.policy.Authorize(c, profile.UserID, auth.ActionRead) {
+			return uuid.Nil, false
+		}
+		return profile.ID, true
+	}
+
+	ownerID := userID.(uuid.UUID)
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return uuid.Nil, false
+		}
+		if !h.policy.Authorize(c, id, auth.ActionRead) {
+			return uuid.Nil, false
+		}
+		ownerID = id
+	}
+
+	profile, err := h.profileService.Default(c.Request.Context(), ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+	return profile.ID, true
+}
+
+// inProfileScope rejects the request with 404 when an optional profile_id query parameter
+// does not match the profile the user medication belongs to
+func inProfileScope(c *gin.Context, profileID uuid.UUID) bool {
+	raw := c.Query("profile_id")
+	if raw == "" {
+		return true
+	}
+	if id, err := uuid.Parse(raw); err == nil && id == profileID {
+		return true
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "user medication not found in profile"})
+	c.Abort()
+	return false
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"backend/internal/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownHandler struct{}
+
+func NewWellKnownHandler() *WellKnownHandler {
+	return &WellKnownHandler{}
+}
+
+// JWKS godoc
+// @Summary      Token signing keys
+// @Description  Get the public keys access tokens are signed with, for services that verify DoseLog tokens. Served at /.well-known/jwks.json outside the /api prefix; empty while tokens are signed with the shared HS256 secret.
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} auth.JSONWebKeySet
+// @Router       /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.PublicKeys())
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"backend/internal/db"
+	"backend/internal/mailer"
+	repository2 "backend/internal/repository"
+	service2 "backend/internal/service"
+	"backend/internal/storage"
+	"context"
+	"log"
+	"time"
+)
+
+// Start launches the periodic background jobs; they stop when ctx is cancelled
+func Start(ctx context.Context) {
+	database := db.GetDB()
+
+	userRepo := repository2.NewUserRepository(database)
+	sessionRepo := repository2.NewSessionRepository(database)
+	medicationRepo := repository2.NewMedicationRepository(database)
+	userMedicationRepo := repository2.NewUserMedicationRepository(database)
+	medicationLogRepo := repository2.NewMedicationLogRepository(database)
+	accountDeletionRepo := repository2.NewAccountDeletionRepository(database)
+	loginThrottleRepo := repository2.NewLoginThrottleRepository(database)
+	careGrantRepo := repository2.NewCareGrantRepository(database)
+	profileRepo := repository2.NewProfileRepository(database)
+
+	mail := mailer.New()
+
+	auditService := service2.NewAuditService(repository2.NewAuditEventRepository(database))
+	attachmentService := service2.NewAttachmentService(repository2.NewAttachmentRepository(database), storage.New(), auditService)
+	accountService := service2.NewAccountService(userRepo, sessionRepo, medicationRepo, userMedicationRepo, medicationLogRepo, accountDeletionRepo, careGrantRepo, profileRepo, mail, attachmentService, auditService)
+	loginThrottleService := service2.NewLoginThrottleService(loginThrottleRepo, mail)
+	oidcStateRepo := repository2.NewOIDCStateRepository(database)
+
+	go every(ctx, time.Hour, "account deletion", accountService.ProcessDueDeletions)
+	go every(ctx, time.Hour, "login throttle cleanup", loginThrottleService.PurgeStale)
+	go every(ctx, time.Hour, "oidc login state cleanup", func(ctx context.Context) error {
+		return oidcStateRepo.DeleteExpired(ctx, time.Now())
+	})
+	go every(ctx, 24*time.Hour, "audit retention", auditService.PurgeExpired)
+}
+
+func every(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			log.Printf("%s job failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"backend/config"
+	"context"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as password reset links
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by config.MailDriver
+func New() Mailer {
+	switch config.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	default:
+		return NewLogMailer(config.MailLogDir)
+	}
+}
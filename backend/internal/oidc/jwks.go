@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// keySet holds the issuer's signing keys by kid
+type keySet struct {
+	byKid map[string]interface{}
+	all   []interface{}
+}
+
+// find returns the key for kid; tokens without kid are accepted only if the issuer has a single key
+func (s *keySet) find(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(s.all) == 1 {
+			return s.all[0], true
+		}
+		return nil, false
+	}
+	key, ok := s.byKid[kid]
+	return key, ok
+}
+
+// parseKeySet converts the signing keys it understands and skips everything else
+func parseKeySet(jwks *jsonWebKeySet) *keySet {
+	set := &keySet{byKid: make(map[string]interface{})}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key := parseKey(k)
+		if key == nil {
+			continue
+		}
+		set.all = append(set.all, key)
+		if k.Kid != "" {
+			set.byKid[k.Kid] = key
+		}
+	}
+	return set
+}
+
+func parseKey(k jsonWebKey) interface{} {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := decodeBigInt(k.N)
+		e, err2 := decodeBigInt(k.E)
+		if err1 != nil || err2 != nil || !e.IsInt64() {
+			return nil
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil
+		}
+		x, err1 := decodeBigInt(k.X)
+		y, err2 := decodeBigInt(k.Y)
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil
+		}
+		return ed25519.PublicKey(x)
+	default:
+		return nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
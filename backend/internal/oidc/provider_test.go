@@ -0,0 +1,288 @@
+package oidc
+
+import (
+	"backend/config"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "doselog"
+	testClientSecret = "secret"
+	testKid          = "key-1"
+	testNonce        = "nonce-1"
+	testVerifier     = "verifier-1"
+)
+
+// testIssuer is an OpenID Connect issuer serving discovery, a token endpoint that returns
+// whatever token the test sets, and a JWKS with a single RSA key
+type testIssuer struct {
+	t           *testing.T
+	server      *httptest.Server
+	key         *rsa.PrivateKey
+	idToken     string
+	jwksFetches atomic.Int32
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	issuer := &testIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JWKSURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != "code-1" ||
+			r.PostFormValue("code_verifier") != testVerifier {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.idToken})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.jwksFetches.Add(1)
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+			Kty: "RSA",
+			Kid: testKid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) provider() *Provider {
+	return NewProvider(config.OIDCProvider{
+		Name:         "test",
+		Issuer:       i.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Scopes:       []string{"openid", "email"},
+	}, "http://localhost/callback")
+}
+
+// claims returns valid ID token claims; tests change them to break one check at a time
+func (i *testIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            testClientID,
+		"sub":            "user-1",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          "user@example.com",
+		"email_verified": true,
+	}
+}
+
+func (i *testIssuer) sign(claims jwt.MapClaims, kid string) string {
+	i.t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		i.t.Fatalf("failed to sign id_token: %v", err)
+	}
+	return signed
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(claims jwt.MapClaims)
+		kid     string
+		nonce   string
+		wantErr string
+	}{
+		{name: "valid token", kid: testKid, nonce: testNonce},
+		{name: "nonce mismatch", kid: testKid, nonce: "other-nonce", wantErr: "nonce mismatch"},
+		{
+			name:    "wrong audience",
+			modify:  func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+			kid:     testKid,
+			nonce:   testNonce,
+			wantErr: "audience",
+		},
+		{
+			name:    "wrong issuer",
+			modify:  func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+			kid:     testKid,
+			nonce:   testNonce,
+			wantErr: "issuer",
+		},
+		{
+			name:    "expired",
+			modify:  func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			kid:     testKid,
+			nonce:   testNonce,
+			wantErr: "expired",
+		},
+		{
+			name:    "missing subject",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "sub") },
+			kid:     testKid,
+			nonce:   testNonce,
+			wantErr: "missing subject",
+		},
+		{name: "unknown kid", kid: "key-2", nonce: testNonce, wantErr: `no signing key found for kid "key-2"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newTestIssuer(t)
+			claims := issuer.claims()
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			issuer.idToken = issuer.sign(claims, tt.kid)
+
+			got, err := issuer.provider().Exchange(context.Background(), "code-1", testVerifier, tt.nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if got.Subject != "user-1" || got.Email != "user@example.com" || !got.IsEmailVerified() {
+				t.Errorf("Exchange() = %+v, want the claims of user-1 with a verified email", got)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.idToken = issuer.sign(issuer.claims(), testKid)
+
+	_, err := issuer.provider().Exchange(context.Background(), "code-1", "other-verifier", testNonce)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange() error = %v, want the token endpoint to refuse the grant", err)
+	}
+}
+
+func TestExchangeRefetchesKeysForUnknownKid(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider()
+
+	issuer.idToken = issuer.sign(issuer.claims(), testKid)
+	if _, err := provider.Exchange(context.Background(), "code-1", testVerifier, testNonce); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), "code-1", testVerifier, testNonce); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if got := issuer.jwksFetches.Load(); got != 1 {
+		t.Fatalf("jwks fetched %d times for a known kid, want 1", got)
+	}
+
+	issuer.idToken = issuer.sign(issuer.claims(), "rotated-key")
+	if _, err := provider.Exchange(context.Background(), "code-1", testVerifier, testNonce); err == nil {
+		t.Fatal("Exchange() accepted a token signed with an unknown kid")
+	}
+	if got := issuer.jwksFetches.Load(); got != 2 {
+		t.Fatalf("jwks fetched %d times after an unknown kid, want 2", got)
+	}
+}
+
+func TestEmailVerifiedClaim(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bool
+	}{
+		{name: "boolean true", value: true, want: true},
+		{name: "boolean false", value: false, want: false},
+		{name: "string true", value: "true", want: true},
+		{name: "string false", value: "false", want: false},
+		{name: "missing", value: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newTestIssuer(t)
+			claims := issuer.claims()
+			if tt.value == nil {
+				delete(claims, "email_verified")
+			} else {
+				claims["email_verified"] = tt.value
+			}
+			issuer.idToken = issuer.sign(claims, testKid)
+
+			got, err := issuer.provider().Exchange(context.Background(), "code-1", testVerifier, testNonce)
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if got.IsEmailVerified() != tt.want {
+				t.Errorf("IsEmailVerified() = %v, want %v", got.IsEmailVerified(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	got, err := issuer.provider().AuthCodeURL(context.Background(), "state-1", testNonce, "challenge-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	for _, want := range []string{
+		issuer.server.URL + "/authorize?",
+		"client_id=" + testClientID,
+		"code_challenge=challenge-1",
+		"code_challenge_method=S256",
+		"nonce=" + testNonce,
+		"state=state-1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("AuthCodeURL() = %s, want it to contain %s", got, want)
+		}
+	}
+}
+
+// TestCodeChallenge uses the example of RFC 7636 appendix B
+func TestCodeChallenge(t *testing.T) {
+	got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge() = %s, want %s", got, want)
+	}
+}
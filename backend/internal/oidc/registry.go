@@ -0,0 +1,40 @@
+package oidc
+
+import (
+	"backend/config"
+	"fmt"
+	"net/url"
+)
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]*Provider
+	order     []string
+}
+
+// NewRegistry builds a provider for each configured issuer; the callback URL is derived from AppBaseURL
+func NewRegistry(providers []config.OIDCProvider) *Registry {
+	registry := &Registry{providers: make(map[string]*Provider)}
+	for _, cfg := range providers {
+		if cfg.Issuer == "" || cfg.ClientID == "" {
+			continue
+		}
+		redirectURL := fmt.Sprintf("%s/api/auth/oidc/%s/callback", config.AppBaseURL, url.PathEscape(cfg.Name))
+		registry.providers[cfg.Name] = NewProvider(cfg, redirectURL)
+		registry.order = append(registry.order, cfg.Name)
+	}
+	return registry
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+func (r *Registry) List() []*Provider {
+	providers := make([]*Provider, len(r.order))
+	for i, name := range r.order {
+		providers[i] = r.providers[name]
+	}
+	return providers
+}
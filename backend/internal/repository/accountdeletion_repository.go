@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountDeletionRepository interface {
+	Create(ctx context.Context, req *entity.AccountDeletionRequest) error
+	GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*entity.AccountDeletionRequest, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.AccountDeletionRequest, error)
+	Cancel(ctx context.Context, id uuid.UUID, cancelledAt time.Time) error
+	CompleteDue(ctx context.Context, now time.Time, limit int) ([]*entity.AccountDeletionRequest, error)
+}
+
+type accountDeletionRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountDeletionRepository(db *sqlx.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{db: db}
+}
+
+func (r *accountDeletionRepository) Create(ctx context.Context, req *entity.AccountDeletionRequest) error {
+	query := `
+		INSERT INTO account_deletion_requests (id, user_id, requested_ip, requested_at, scheduled_for)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.ExecContext(ctx, query, req.ID, req.UserID, req.RequestedIP, req.RequestedAt, req.ScheduledFor)
+	return err
+}
+
+func (r *accountDeletionRepository) GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*entity.AccountDeletionRequest, error) {
+	var req entity.AccountDeletionRequest
+	query := `
+		SELECT id, user_id, requested_ip, requested_at, scheduled_for, cancelled_at, completed_at
+		FROM account_deletion_requests
+		WHERE user_id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+	`
+	err := r.db.GetContext(ctx, &req, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *accountDeletionRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.AccountDeletionRequest, error) {
+	var requests []*entity.AccountDeletionRequest
+	query := `
+		SELECT id, user_id, requested_ip, requested_at, scheduled_for, cancelled_at, completed_at
+		FROM account_deletion_requests
+		WHERE user_id = $1
+		ORDER BY requested_at DESC
+	`
+	err := r.db.SelectContext(ctx, &requests, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *accountDeletionRepository) Cancel(ctx context.Context, id uuid.UUID, cancelledAt time.Time) error {
+	query := `
+		UPDATE account_deletion_requests
+		SET cancelled_at = $2
+		WHERE id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, cancelledAt)
+	return err
+}
+
+// CompleteDue deletes the users whose grace period has ended and marks their requests completed.
+// Audit events about their data hold the same health data in their snapshots, so they go too.
+// Rows are locked with SKIP LOCKED so several backend instances can run the job concurrently.
+func (r *accountDeletionRepository) CompleteDue(ctx context.Context, now time.Time, limit int) ([]*entity.AccountDeletionRequest, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var requests []*entity.AccountDeletionRequest
+	query := `
+		SELECT id, user_id, requested_ip, requested_at, scheduled_for, cancelled_at, completed_at
+		FROM account_deletion_requests
+		WHERE scheduled_for <= $1 AND cancelled_at IS NULL AND completed_at IS NULL
+		ORDER BY scheduled_for
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	if err := tx.SelectContext(ctx, &requests, query, now, limit); err != nil {
+		return nil, err
+	}
+
+	filesQuery := `
+		SELECT file_key
+		FROM medication_attachments a
+		JOIN medications m ON m.id = a.medication_id
+		CROSS JOIN LATERAL (VALUES (a.storage_key), (a.thumbnail_key)) AS k(file_key)
+		WHERE m.owner_id = $1 AND file_key IS NOT NULL
+	`
+	for _, req := range requests {
+		if err := tx.SelectContext(ctx, &req.AttachmentFiles, filesQuery, req.UserID); err != nil {
+			return nil, err
+		}
+		// user_medications, medication_logs, sessions and private medications follow through ON DELETE CASCADE
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, req.UserID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM audit_events WHERE subject_user_id = $1`, req.UserID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE account_deletion_requests SET completed_at = $2 WHERE id = $1`, req.ID, now); err != nil {
+			return nil, err
+		}
+		completedAt := now
+		req.CompletedAt = &completedAt
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *entity.MedicationAttachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.MedicationAttachment, error)
+	ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.MedicationAttachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type attachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *entity.MedicationAttachment) error {
+	query := `
+		INSERT INTO medication_attachments (id, medication_id, kind, file_name, content_type, size_bytes, storage_key, thumbnail_key, uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.ExecContext(ctx, query, attachment.ID, attachment.MedicationID, attachment.Kind, attachment.FileName,
+		attachment.ContentType, attachment.SizeBytes, attachment.StorageKey, attachment.ThumbnailKey, attachment.UploadedBy, attachment.CreatedAt)
+	return err
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.MedicationAttachment, error) {
+	var attachment entity.MedicationAttachment
+	query := `
+		SELECT id, medication_id, kind, file_name, content_type, size_bytes, storage_key, thumbnail_key, uploaded_by, created_at
+		FROM medication_attachments
+		WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &attachment, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.MedicationAttachment, error) {
+	var attachments []*entity.MedicationAttachment
+	query := `
+		SELECT id, medication_id, kind, file_name, content_type, size_bytes, storage_key, thumbnail_key, uploaded_by, created_at
+		FROM medication_attachments
+		WHERE medication_id = $1
+		ORDER BY created_at
+	`
+	if err := r.db.SelectContext(ctx, &attachments, query, medicationID); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM medication_attachments WHERE id = $1`, id)
+	return err
+}
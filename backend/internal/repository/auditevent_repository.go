@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *entity.AuditEvent) error
+	List(ctx context.Context, filter entity.AuditEventFilter) ([]*entity.AuditEvent, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type auditEventRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditEventRepository(db *sqlx.DB) AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (id, occurred_at, actor_id, subject_user_id, session_id, token_id, ip_address, user_agent,
+		                          action, entity_type, entity_id, before, after, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID, event.OccurredAt, event.ActorID, event.SubjectUserID, event.SessionID, event.TokenID, event.IPAddress, event.UserAgent,
+		event.Action, event.EntityType, event.EntityID, event.Before, event.After, event.Changes)
+	return err
+}
+
+// List returns matching events, newest first
+func (r *auditEventRepository) List(ctx context.Context, filter entity.AuditEventFilter) ([]*entity.AuditEvent, error) {
+	var events []*entity.AuditEvent
+	query := `
+		SELECT id, occurred_at, actor_id, subject_user_id, session_id, token_id, ip_address, user_agent,
+		       action, entity_type, entity_id, before, after, changes
+		FROM audit_events
+		WHERE ($1::uuid IS NULL OR actor_id = $1 OR subject_user_id = $1)
+		  AND ($2 = '' OR entity_type = $2)
+		  AND ($3::uuid IS NULL OR entity_id = $3)
+		  AND ($4 = '' OR action = $4)
+		  AND ($5::timestamptz IS NULL OR occurred_at >= $5)
+		  AND ($6::timestamptz IS NULL OR occurred_at < $6)
+		ORDER BY occurred_at DESC, id
+		LIMIT $7 OFFSET $8
+	`
+	err := r.db.SelectContext(ctx, &events, query,
+		filter.UserID, filter.EntityType, filter.EntityID, filter.Action, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeleteBefore removes events older than the cutoff and returns how many were removed
+func (r *auditEventRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		DELETE FROM audit_events
+		WHERE occurred_at < $1
+	`
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type CareGrantRepository interface {
+	Create(ctx context.Context, grant *entity.CareGrant) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.CareGrant, error)
+	GetByInviteTokenHash(ctx context.Context, hash string) (*entity.CareGrant, error)
+	GetActive(ctx context.Context, patientID, caregiverID uuid.UUID) (*entity.CareGrant, error)
+	ListByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entity.CareGrant, error)
+	ListActiveByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entity.CareGrant, error)
+	Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+}
+
+type careGrantRepository struct {
+	db *sqlx.DB
+}
+
+func NewCareGrantRepository(db *sqlx.DB) CareGrantRepository {
+	return &careGrantRepository{db: db}
+}
+
+const careGrantColumns = `id, patient_id, caregiver_email, caregiver_id, permission, invite_token_hash,
+		       invite_expires_at, accepted_at, revoked_at, created_at`
+
+func (r *careGrantRepository) Create(ctx context.Context, grant *entity.CareGrant) error {
+	query := `
+		INSERT INTO care_grants (id, patient_id, caregiver_email, permission, invite_token_hash, invite_expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query, grant.ID, grant.PatientID, grant.CaregiverEmail, grant.Permission,
+		grant.InviteTokenHash, grant.InviteExpiresAt, grant.CreatedAt)
+	return err
+}
+
+func (r *careGrantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CareGrant, error) {
+	return r.getOne(ctx, `SELECT `+careGrantColumns+` FROM care_grants WHERE id = $1`, id)
+}
+
+func (r *careGrantRepository) GetByInviteTokenHash(ctx context.Context, hash string) (*entity.CareGrant, error) {
+	return r.getOne(ctx, `SELECT `+careGrantColumns+` FROM care_grants WHERE invite_token_hash = $1`, hash)
+}
+
+// GetActive returns the accepted, unrevoked grant a caregiver holds for a patient
+func (r *careGrantRepository) GetActive(ctx context.Context, patientID, caregiverID uuid.UUID) (*entity.CareGrant, error) {
+	query := `
+		SELECT ` + careGrantColumns + `
+		FROM care_grants
+		WHERE patient_id = $1 AND caregiver_id = $2
+		  AND accepted_at IS NOT NULL AND revoked_at IS NULL
+	`
+	return r.getOne(ctx, query, patientID, caregiverID)
+}
+
+func (r *careGrantRepository) ListByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entity.CareGrant, error) {
+	var grants []*entity.CareGrant
+	query := `
+		SELECT ` + careGrantColumns + `
+		FROM care_grants
+		WHERE patient_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &grants, query, patientID); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *careGrantRepository) ListActiveByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entity.CareGrant, error) {
+	var grants []*entity.CareGrant
+	query := `
+		SELECT ` + careGrantColumns + `
+		FROM care_grants
+		WHERE caregiver_id = $1 AND accepted_at IS NOT NULL AND revoked_at IS NULL
+		ORDER BY accepted_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &grants, query, caregiverID); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// Accept binds the invite to the caregiver and reports false if it was already used, revoked or expired
+func (r *careGrantRepository) Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error) {
+	query := `
+		UPDATE care_grants
+		SET caregiver_id = $2, accepted_at = $3
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND invite_expires_at > $3
+	`
+	result, err := r.db.ExecContext(ctx, query, id, caregiverID, acceptedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *careGrantRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	query := `
+		UPDATE care_grants
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	return err
+}
+
+func (r *careGrantRepository) getOne(ctx context.Context, query string, args ...interface{}) (*entity.CareGrant, error) {
+	var grant entity.CareGrant
+	err := r.db.GetContext(ctx, &grant, query, args...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
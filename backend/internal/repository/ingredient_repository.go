@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IngredientRepository interface {
+	ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.MedicationIngredient, error)
+	ReplaceForMedication(ctx context.Context, medicationID uuid.UUID, ingredients []*entity.MedicationIngredient) error
+	UpsertInteraction(ctx context.Context, interaction *entity.Interaction) error
+	FindInteractions(ctx context.Context, medicationID uuid.UUID, otherMedicationIDs []uuid.UUID) ([]*entity.InteractionMatch, error)
+}
+
+type ingredientRepository struct {
+	db *sqlx.DB
+}
+
+func NewIngredientRepository(db *sqlx.DB) IngredientRepository {
+	return &ingredientRepository{db: db}
+}
+
+// ensureIngredient returns the id of the ingredient with this name (case-insensitive), creating it if needed
+func ensureIngredient(ctx context.Context, tx *sqlx.Tx, name string) (uuid.UUID, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO ingredients (id, name, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT ((lower(name))) DO UPDATE SET name = ingredients.name
+		RETURNING id
+	`
+	err := tx.GetContext(ctx, &id, query, uuid.New(), name, time.Now())
+	return id, err
+}
+
+func (r *ingredientRepository) ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.MedicationIngredient, error) {
+	var ingredients []*entity.MedicationIngredient
+	query := `
+		SELECT mi.medication_id, mi.ingredient_id, i.name AS ingredient_name, mi.amount_mg
+		FROM medication_ingredients mi
+		JOIN ingredients i ON i.id = mi.ingredient_id
+		WHERE mi.medication_id = $1
+		ORDER BY i.name
+	`
+	if err := r.db.SelectContext(ctx, &ingredients, query, medicationID); err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+// ReplaceForMedication sets the medication's ingredients atomically, creating unknown ingredients by name
+func (r *ingredientRepository) ReplaceForMedication(ctx context.Context, medicationID uuid.UUID, ingredients []*entity.MedicationIngredient) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM medication_ingredients WHERE medication_id = $1`, medicationID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO medication_ingredients (medication_id, ingredient_id, amount_mg)
+		VALUES ($1, $2, $3)
+	`
+	for _, ingredient := range ingredients {
+		id, err := ensureIngredient(ctx, tx, ingredient.IngredientName)
+		if err != nil {
+			return err
+		}
+		ingredient.MedicationID = medicationID
+		ingredient.IngredientID = id
+		if _, err := tx.ExecContext(ctx, query, medicationID, id, ingredient.AmountMg); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpsertInteraction stores or replaces the interaction for an ingredient pair, creating the ingredients if needed
+func (r *ingredientRepository) UpsertInteraction(ctx context.Context, interaction *entity.Interaction) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	a, err := ensureIngredient(ctx, tx, interaction.IngredientA)
+	if err != nil {
+		return err
+	}
+	b, err := ensureIngredient(ctx, tx, interaction.IngredientB)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO ingredient_interactions (ingredient_a_id, ingredient_b_id, severity, description, updated_at)
+		VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid), $3, $4, $5)
+		ON CONFLICT (ingredient_a_id, ingredient_b_id)
+		DO UPDATE SET severity = EXCLUDED.severity, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.ExecContext(ctx, query, a, b, interaction.Severity, interaction.Description, interaction.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindInteractions lists interactions between the medication's ingredients and those of the other
+// medications, most severe first
+func (r *ingredientRepository) FindInteractions(ctx context.Context, medicationID uuid.UUID, otherMedicationIDs []uuid.UUID) ([]*entity.InteractionMatch, error) {
+	var matches []*entity.InteractionMatch
+	if len(otherMedicationIDs) == 0 {
+		return matches, nil
+	}
+
+	ids := make([]string, len(otherMedicationIDs))
+	for i, id := range otherMedicationIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		SELECT om.id AS other_medication_id, om.name AS other_medication_name,
+		       i.name AS ingredient, oi.name AS other_ingredient, ii.severity, ii.description
+		FROM medication_ingredients mi
+		JOIN medication_ingredients omi ON omi.medication_id = ANY($2::uuid[])
+		JOIN ingredient_interactions ii
+		  ON ii.ingredient_a_id = LEAST(mi.ingredient_id, omi.ingredient_id)
+		 AND ii.ingredient_b_id = GREATEST(mi.ingredient_id, omi.ingredient_id)
+		JOIN ingredients i ON i.id = mi.ingredient_id
+		JOIN ingredients oi ON oi.id = omi.ingredient_id
+		JOIN medications om ON om.id = omi.medication_id
+		WHERE mi.medication_id = $1
+		ORDER BY CASE ii.severity WHEN 'severe' THEN 0 WHEN 'moderate' THEN 1 ELSE 2 END, om.name, i.name
+	`
+	if err := r.db.SelectContext(ctx, &matches, query, medicationID, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
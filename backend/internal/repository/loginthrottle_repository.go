@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, key string) (*entity.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (*entity.LoginThrottle, error)
+	Lock(ctx context.Context, key string, lockedUntil time.Time) error
+	Reset(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, before time.Time) error
+}
+
+type loginThrottleRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginThrottleRepository(db *sqlx.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Get(ctx context.Context, key string) (*entity.LoginThrottle, error) {
+	var throttle entity.LoginThrottle
+	query := `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_throttles
+		WHERE key = $1
+	`
+	err := r.db.GetContext(ctx, &throttle, query, key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailure atomically increments the failure counter, restarting it when the
+// previous failure is older than windowStart, and returns the updated row
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (*entity.LoginThrottle, error) {
+	var throttle entity.LoginThrottle
+	query := `
+		INSERT INTO login_throttles (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE WHEN login_throttles.last_failure_at < $3 THEN 1 ELSE login_throttles.failures + 1 END,
+		    last_failure_at = $2
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+	err := r.db.GetContext(ctx, &throttle, query, key, now, windowStart)
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, lockedUntil time.Time) error {
+	query := `
+		UPDATE login_throttles
+		SET locked_until = GREATEST(COALESCE(locked_until, $2), $2)
+		WHERE key = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, key, lockedUntil)
+	return err
+}
+
+func (r *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE key = $1`, key)
+	return err
+}
+
+// DeleteStale removes counters that have neither a recent failure nor an active lock
+func (r *loginThrottleRepository) DeleteStale(ctx context.Context, before time.Time) error {
+	query := `
+		DELETE FROM login_throttles
+		WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)
+	`
+	_, err := r.db.ExecContext(ctx, query, before)
+	return err
+}
@@ -2,19 +2,38 @@ package repository
 
 import (
 	"backend/internal/core/entity"
+	"backend/internal/core/shared"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// foreignKeyViolation is the PostgreSQL error code of a statement that breaks a foreign key
+const foreignKeyViolation = "23503"
+
 type MedicationRepository interface {
 	Create(ctx context.Context, med *entity.Medication) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Medication, error)
-	GetByName(ctx context.Context, name string) (*entity.Medication, error)
+	GetByName(ctx context.Context, name string, ownerID *uuid.UUID) (*entity.Medication, error)
+	GetByExternalCode(ctx context.Context, code string, ownerID *uuid.UUID) (*entity.Medication, error)
+	ListByBarcodes(ctx context.Context, codes []string) ([]*entity.Medication, error)
 	Update(ctx context.Context, med *entity.Medication) error
-	List(ctx context.Context, limit, offset int) ([]*entity.Medication, error)
+	Search(ctx context.Context, filter entity.MedicationSearchFilter) ([]*entity.MedicationSearchHit, int, error)
+	ListByStatus(ctx context.Context, status shared.MedicationStatus, limit, offset int) ([]*entity.Medication, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status shared.MedicationStatus, reviewedBy uuid.UUID, reviewedAt time.Time) error
+	SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time, archivedBy *uuid.UUID) error
+	CountReferences(ctx context.Context, id uuid.UUID) (int, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) ([]*entity.UserMedication, error)
+	FindDuplicates(ctx context.Context, query entity.MedicationDuplicateQuery) ([]*entity.MedicationDuplicate, error)
 }
 
 type medicationRepository struct {
@@ -27,19 +46,22 @@ func NewMedicationRepository(db *sqlx.DB) MedicationRepository {
 
 func (r *medicationRepository) Create(ctx context.Context, med *entity.Medication) error {
 	query := `
-		INSERT INTO medications (id, name, description, manufacturer, form, strength_mg, pills_per_box, meal_relation, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO medications (id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		                         visibility, owner_id, status, submitted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		med.ID, med.Name, med.Description, med.Manufacturer,
-		med.Form, med.StrengthMg, med.PillsPerBox, med.MealRelation, med.CreatedAt)
+		med.Form, med.Strength, med.StrengthUnit, med.PackageSize, med.PackageUnit, med.MealRelation,
+		med.ExternalCode, med.Barcodes, med.Visibility, med.OwnerID, med.Status, med.SubmittedBy, med.CreatedAt)
 	return err
 }
 
 func (r *medicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Medication, error) {
 	var med entity.Medication
 	query := `
-		SELECT id, name, description, manufacturer, form, strength_mg, pills_per_box, meal_relation, created_at
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at
 		FROM medications
 		WHERE id = $1
 	`
@@ -53,14 +75,37 @@ func (r *medicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*enti
 	return &med, nil
 }
 
-func (r *medicationRepository) GetByName(ctx context.Context, name string) (*entity.Medication, error) {
+// GetByName looks the name up in the shared catalog when ownerID is nil, else among that user's private entries
+func (r *medicationRepository) GetByName(ctx context.Context, name string, ownerID *uuid.UUID) (*entity.Medication, error) {
 	var med entity.Medication
 	query := `
-		SELECT id, name, description, manufacturer, form, strength_mg, pills_per_box, meal_relation, created_at
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at
 		FROM medications
 		WHERE name = $1
+		  AND owner_id IS NOT DISTINCT FROM $2
+	`
+	err := r.db.GetContext(ctx, &med, query, name, ownerID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &med, nil
+}
+
+// GetByExternalCode looks the code up in the shared catalog when ownerID is nil, else among that user's private entries
+func (r *medicationRepository) GetByExternalCode(ctx context.Context, code string, ownerID *uuid.UUID) (*entity.Medication, error) {
+	var med entity.Medication
+	query := `
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at
+		FROM medications
+		WHERE external_code = $1
+		  AND owner_id IS NOT DISTINCT FROM $2
 	`
-	err := r.db.GetContext(ctx, &med, query, name)
+	err := r.db.GetContext(ctx, &med, query, code, ownerID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -70,30 +115,325 @@ func (r *medicationRepository) GetByName(ctx context.Context, name string) (*ent
 	return &med, nil
 }
 
+// ListByBarcodes returns every entry, of any visibility, status or archival, carrying one of the codes
+func (r *medicationRepository) ListByBarcodes(ctx context.Context, codes []string) ([]*entity.Medication, error) {
+	var medications []*entity.Medication
+	query := `
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at
+		FROM medications
+		WHERE barcodes && $1
+		ORDER BY created_at
+	`
+	if err := r.db.SelectContext(ctx, &medications, query, pq.StringArray(codes)); err != nil {
+		return nil, err
+	}
+	return medications, nil
+}
+
 func (r *medicationRepository) Update(ctx context.Context, med *entity.Medication) error {
 	query := `
 		UPDATE medications
 		SET name = $2, description = $3, manufacturer = $4, form = $5,
-		    strength_mg = $6, pills_per_box = $7, meal_relation = $8
+		    strength = $6, strength_unit = $7, package_size = $8, package_unit = $9,
+		    meal_relation = $10, external_code = $11, barcodes = $12
 		WHERE id = $1
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		med.ID, med.Name, med.Description, med.Manufacturer,
-		med.Form, med.StrengthMg, med.PillsPerBox, med.MealRelation)
+		med.Form, med.Strength, med.StrengthUnit, med.PackageSize, med.PackageUnit,
+		med.MealRelation, med.ExternalCode, med.Barcodes)
 	return err
 }
 
-func (r *medicationRepository) List(ctx context.Context, limit, offset int) ([]*entity.Medication, error) {
+// medicationSortColumns maps sortable fields to their SQL expression and the type cursor keys are cast back to.
+// {query} in an expression stands for the query text parameter.
+var medicationSortColumns = map[shared.MedicationSort]struct{ expr, cast string }{
+	shared.SortByRelevance: {"similarity(lower(name), {query})", "real"},
+	shared.SortByName:      {"lower(name)", "text"},
+	shared.SortByStrength:  {"COALESCE(strength_mg, 0)", "real"},
+	shared.SortByCreatedAt: {"created_at", "timestamptz"},
+}
+
+// Search returns one page of approved shared medications and of the viewer's private ones, leaving out
+// archived entries, matching the filter, plus the total number of matches ignoring paging. Sorting by relevance requires a query.
+func (r *medicationRepository) Search(ctx context.Context, filter entity.MedicationSearchFilter) ([]*entity.MedicationSearchHit, int, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{
+		"archived_at IS NULL",
+		"(visibility = 'shared' AND status = 'approved' OR owner_id = " + arg(filter.ViewerID) + ")",
+	}
+	if filter.Visibility != "" {
+		conditions = append(conditions, "visibility = "+arg(filter.Visibility))
+	}
+	queryParam := "NULL"
+	if filter.Query != "" {
+		queryParam = arg(strings.ToLower(filter.Query))
+		conditions = append(conditions, "(lower(name) % "+queryParam+" OR lower(name) LIKE "+arg("%"+escapeLike(strings.ToLower(filter.Query))+"%")+")")
+	}
+	if len(filter.Forms) > 0 {
+		conditions = append(conditions, "form = ANY("+arg(pq.Array(filter.Forms))+")")
+	}
+	if filter.Manufacturer != "" {
+		conditions = append(conditions, "lower(manufacturer) LIKE "+arg("%"+escapeLike(strings.ToLower(filter.Manufacturer))+"%"))
+	}
+	if filter.StrengthMin != nil {
+		conditions = append(conditions, "strength_mg >= "+arg(*filter.StrengthMin))
+	}
+	if filter.StrengthMax != nil {
+		conditions = append(conditions, "strength_mg <= "+arg(*filter.StrengthMax))
+	}
+	if filter.MealRelation != "" {
+		conditions = append(conditions, "meal_relation::text = "+arg(filter.MealRelation))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM medications WHERE `+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := medicationSortColumns[filter.Sort]
+	if !ok || (filter.Sort == shared.SortByRelevance && filter.Query == "") {
+		column = medicationSortColumns[shared.SortByCreatedAt]
+	}
+	column.expr = strings.ReplaceAll(column.expr, "{query}", queryParam)
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		where += fmt.Sprintf(" AND (%s, id) %s (%s::%s, %s)",
+			column.expr, comparison, arg(filter.After.Key), column.cast, arg(filter.After.ID))
+	}
+
+	query := `
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at,
+		       (` + column.expr + `)::text AS sort_key
+		FROM medications
+		WHERE ` + where + `
+		ORDER BY ` + column.expr + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + arg(filter.Limit)
+
+	var hits []*entity.MedicationSearchHit
+	if err := r.db.SelectContext(ctx, &hits, query, args...); err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// escapeLike makes user input match literally inside a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *medicationRepository) ListByStatus(ctx context.Context, status shared.MedicationStatus, limit, offset int) ([]*entity.Medication, error) {
 	var medications []*entity.Medication
 	query := `
-		SELECT id, name, description, manufacturer, form, strength_mg, pills_per_box, meal_relation, created_at
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at
 		FROM medications
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE status = $1
+		  AND archived_at IS NULL
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3
 	`
-	err := r.db.SelectContext(ctx, &medications, query, limit, offset)
+	err := r.db.SelectContext(ctx, &medications, query, status, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	return medications, nil
 }
+
+func (r *medicationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status shared.MedicationStatus, reviewedBy uuid.UUID, reviewedAt time.Time) error {
+	query := `
+		UPDATE medications
+		SET status = $2, reviewed_by = $3, reviewed_at = $4
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, status, reviewedBy, reviewedAt)
+	return err
+}
+
+// SetArchived archives the medication, or restores it when archivedAt is nil
+func (r *medicationRepository) SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time, archivedBy *uuid.UUID) error {
+	query := `
+		UPDATE medications
+		SET archived_at = $2, archived_by = $3
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, archivedAt, archivedBy)
+	return err
+}
+
+// CountReferences returns how many user medications track the medication
+func (r *medicationRepository) CountReferences(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_medications WHERE medication_id = $1`
+	err := r.db.GetContext(ctx, &count, query, id)
+	return count, err
+}
+
+// Delete removes the medication unless a user medication tracks it; it reports whether a row was deleted.
+// A tracking started concurrently slips past the NOT EXISTS but trips the foreign key, which is
+// reported the same way, so it cannot be lost.
+func (r *medicationRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM medications
+		WHERE id = $1
+		  AND NOT EXISTS (SELECT 1 FROM user_medications WHERE medication_id = $1)
+	`
+	result, err := r.db.ExecContext(ctx, query, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Merge moves every user medication, attachment, barcode and any missing ingredient from source to target and deletes
+// source, atomically. It returns the moved user medications. A profile tracking both entries is a
+// conflict the caller has to resolve first.
+func (r *medicationRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID) ([]*entity.UserMedication, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock both rows so neither can be deleted or merged elsewhere meanwhile
+	var locked int
+	if err := tx.GetContext(ctx, &locked, `SELECT COUNT(*) FROM (SELECT id FROM medications WHERE id IN ($1, $2) FOR UPDATE) m`, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if locked != 2 {
+		return nil, fmt.Errorf("medication not found")
+	}
+
+	// The caller compared the entries already; this repeats it under the lock in case one changed since
+	var compatible bool
+	compatibleQuery := `
+		SELECT COALESCE(s.form = t.form AND s.package_unit = t.package_unit
+		       AND (s.strength_unit = t.strength_unit AND s.strength = t.strength
+		            OR abs(s.strength_mg - t.strength_mg) <= 0.0001 * t.strength_mg), false)
+		FROM medications s, medications t
+		WHERE s.id = $1 AND t.id = $2
+	`
+	if err := tx.GetContext(ctx, &compatible, compatibleQuery, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if !compatible {
+		return nil, fmt.Errorf("medications no longer have the same form, package unit and strength")
+	}
+
+	var conflicts int
+	conflictQuery := `
+		SELECT COUNT(*)
+		FROM user_medications s
+		JOIN user_medications t ON t.profile_id = s.profile_id
+		WHERE s.medication_id = $1 AND t.medication_id = $2
+	`
+	if err := tx.GetContext(ctx, &conflicts, conflictQuery, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if conflicts > 0 {
+		return nil, fmt.Errorf("%d profiles track both medications; end one of the trackings first", conflicts)
+	}
+
+	var moved []*entity.UserMedication
+	moveQuery := `
+		UPDATE user_medications
+		SET medication_id = $2
+		WHERE medication_id = $1
+		RETURNING id, user_id, profile_id, medication_id
+	`
+	if err := tx.SelectContext(ctx, &moved, moveQuery, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	ingredientQuery := `
+		INSERT INTO medication_ingredients (medication_id, ingredient_id, amount_mg)
+		SELECT $2, ingredient_id, amount_mg
+		FROM medication_ingredients
+		WHERE medication_id = $1
+		ON CONFLICT (medication_id, ingredient_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, ingredientQuery, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	barcodeQuery := `
+		UPDATE medications t
+		SET barcodes = ARRAY(SELECT DISTINCT code FROM unnest(t.barcodes || s.barcodes) AS code ORDER BY code)
+		FROM medications s
+		WHERE t.id = $2 AND s.id = $1
+	`
+	if _, err := tx.ExecContext(ctx, barcodeQuery, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	// Attachments keep their storage keys, so the files need not move
+	if _, err := tx.ExecContext(ctx, `UPDATE medication_attachments SET medication_id = $2 WHERE medication_id = $1`, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM medications WHERE id = $1`, sourceID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// minDuplicateSimilarity is the trigram similarity of normalised names above which entries of the
+// same form and strength are reported as probable duplicates
+const minDuplicateSimilarity = 0.5
+
+// FindDuplicates returns probable duplicates, exact normalised name matches first
+func (r *medicationRepository) FindDuplicates(ctx context.Context, query entity.MedicationDuplicateQuery) ([]*entity.MedicationDuplicate, error) {
+	// Masses are compared in mg so 0.5 g and 500 mg match; other units must match as given
+	var strengthMg *float64
+	if mg, ok := query.StrengthUnit.Milligrams(float64(query.Strength)); ok {
+		strengthMg = &mg
+	}
+
+	var duplicates []*entity.MedicationDuplicate
+	sqlQuery := `
+		WITH candidate AS (SELECT normalize_medication_name($1) AS name)
+		SELECT id, name, description, manufacturer, form, strength, strength_unit, package_size, package_unit, meal_relation, external_code, barcodes,
+		       visibility, owner_id, status, submitted_by, reviewed_by, reviewed_at, archived_at, archived_by, created_at,
+		       similarity(normalized_name, candidate.name) AS score,
+		       normalized_name = candidate.name AS same_name
+		FROM medications, candidate
+		WHERE archived_at IS NULL
+		  AND id IS DISTINCT FROM $2
+		  AND (visibility = 'shared' OR owner_id = $3)
+		  AND form = $4
+		  AND (strength_unit = $5 AND strength = $6::real
+		       OR $7::double precision IS NOT NULL AND abs(strength_mg - $7) <= 0.0001 * $7)
+		  AND (normalized_name = candidate.name
+		       OR normalized_name % candidate.name AND similarity(normalized_name, candidate.name) >= $8)
+		ORDER BY same_name DESC, score DESC, created_at
+		LIMIT $9
+	`
+	err := r.db.SelectContext(ctx, &duplicates, sqlQuery,
+		query.Name, query.ExcludeID, query.OwnerID, query.Form,
+		query.StrengthUnit, query.Strength, strengthMg, minDuplicateSimilarity, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
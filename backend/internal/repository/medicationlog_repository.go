@@ -28,17 +28,17 @@ func NewMedicationLogRepository(db *sqlx.DB) MedicationLogRepository {
 
 func (r *medicationLogRepository) Create(ctx context.Context, log *entity.MedicationLog) error {
 	query := `
-		INSERT INTO medication_logs (id, user_medication_id, time_slot, planned_dose, taken, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO medication_logs (id, user_medication_id, time_slot, planned_dose, dose_unit, taken, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := r.db.ExecContext(ctx, query, log.ID, log.UserMedicationID, log.TimeSlot, log.PlannedDose, log.Taken, log.Timestamp)
+	_, err := r.db.ExecContext(ctx, query, log.ID, log.UserMedicationID, log.TimeSlot, log.PlannedDose, log.DoseUnit, log.Taken, log.Timestamp)
 	return err
 }
 
 func (r *medicationLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.MedicationLog, error) {
 	var log entity.MedicationLog
 	query := `
-		SELECT id, user_medication_id, time_slot, planned_dose, taken, timestamp
+		SELECT id, user_medication_id, time_slot, planned_dose, dose_unit, taken, timestamp, taken_by, taken_at
 		FROM medication_logs
 		WHERE id = $1
 	`
@@ -55,7 +55,7 @@ func (r *medicationLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*e
 func (r *medicationLogRepository) GetByUserMedicationID(ctx context.Context, userMedicationID uuid.UUID) ([]*entity.MedicationLog, error) {
 	var logs []*entity.MedicationLog
 	query := `
-		SELECT id, user_medication_id, time_slot, planned_dose, taken, timestamp
+		SELECT id, user_medication_id, time_slot, planned_dose, dose_unit, taken, timestamp, taken_by, taken_at
 		FROM medication_logs
 		WHERE user_medication_id = $1
 		ORDER BY timestamp DESC
@@ -70,7 +70,7 @@ func (r *medicationLogRepository) GetByUserMedicationID(ctx context.Context, use
 func (r *medicationLogRepository) GetByUserMedicationIDAndDateRange(ctx context.Context, userMedicationID uuid.UUID, start, end time.Time) ([]*entity.MedicationLog, error) {
 	var logs []*entity.MedicationLog
 	query := `
-		SELECT id, user_medication_id, time_slot, planned_dose, taken, timestamp
+		SELECT id, user_medication_id, time_slot, planned_dose, dose_unit, taken, timestamp, taken_by, taken_at
 		FROM medication_logs
 		WHERE user_medication_id = $1
 		  AND timestamp >= $2
@@ -87,9 +87,9 @@ func (r *medicationLogRepository) GetByUserMedicationIDAndDateRange(ctx context.
 func (r *medicationLogRepository) Update(ctx context.Context, log *entity.MedicationLog) error {
 	query := `
 		UPDATE medication_logs
-		SET taken = $2
+		SET taken = $2, taken_by = $3, taken_at = $4
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query, log.ID, log.Taken)
+	_, err := r.db.ExecContext(ctx, query, log.ID, log.Taken, log.TakenBy, log.TakenAt)
 	return err
 }
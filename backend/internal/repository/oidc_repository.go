@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *entity.OIDCLoginState) error
+	Consume(ctx context.Context, stateHash string) (*entity.OIDCLoginState, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+type oidcStateRepository struct {
+	db *sqlx.DB
+}
+
+func NewOIDCStateRepository(db *sqlx.DB) OIDCStateRepository {
+	return &oidcStateRepository{db: db}
+}
+
+func (r *oidcStateRepository) Create(ctx context.Context, state *entity.OIDCLoginState) error {
+	query := `
+		INSERT INTO oidc_login_states (state_hash, provider, code_verifier, nonce, device_name, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query, state.StateHash, state.Provider, state.CodeVerifier, state.Nonce,
+		state.DeviceName, state.ExpiresAt, state.CreatedAt)
+	return err
+}
+
+// Consume deletes and returns the state so each authorization response can be used once
+func (r *oidcStateRepository) Consume(ctx context.Context, stateHash string) (*entity.OIDCLoginState, error) {
+	var state entity.OIDCLoginState
+	query := `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, provider, code_verifier, nonce, device_name, expires_at, created_at
+	`
+	err := r.db.GetContext(ctx, &state, query, stateHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *oidcStateRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	query := `DELETE FROM oidc_login_states WHERE expires_at <= $1`
+	_, err := r.db.ExecContext(ctx, query, now)
+	return err
+}
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserIdentity, error)
+	TouchLastLogin(ctx context.Context, id uuid.UUID, email *string, at time.Time) error
+}
+
+type userIdentityRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserIdentityRepository(db *sqlx.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query, identity.ID, identity.UserID, identity.Provider, identity.Subject,
+		identity.Email, identity.CreatedAt, identity.LastLoginAt)
+	return err
+}
+
+func (r *userIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+	err := r.db.GetContext(ctx, &identity, query, provider, subject)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserIdentity, error) {
+	var identities []*entity.UserIdentity
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	if err := r.db.SelectContext(ctx, &identities, query, userID); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *userIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, email *string, at time.Time) error {
+	query := `
+		UPDATE user_identities
+		SET last_login_at = $2, email = COALESCE($3, email)
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, at, email)
+	return err
+}
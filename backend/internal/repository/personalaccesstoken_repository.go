@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *entity.PersonalAccessToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PersonalAccessToken, error)
+	GetByTokenHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PersonalAccessToken, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error
+}
+
+type personalAccessTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewPersonalAccessTokenRepository(db *sqlx.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+func (r *personalAccessTokenRepository) Create(ctx context.Context, token *entity.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (id, user_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.UserID, token.Name, token.TokenPrefix, token.TokenHash,
+		token.Scopes, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+func (r *personalAccessTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PersonalAccessToken, error) {
+	var token entity.PersonalAccessToken
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &token, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) GetByTokenHash(ctx context.Context, hash string) (*entity.PersonalAccessToken, error) {
+	var token entity.PersonalAccessToken
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1
+	`
+	err := r.db.GetContext(ctx, &token, query, hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PersonalAccessToken, error) {
+	var tokens []*entity.PersonalAccessToken
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &tokens, query, userID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// TouchLastUsed records usage at most once a minute so busy scripts do not write on every request
+func (r *personalAccessTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = $2, last_used_ip = $3
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - interval '1 minute')
+	`
+	_, err := r.db.ExecContext(ctx, query, id, at, ip)
+	return err
+}
+
+func (r *personalAccessTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	return err
+}
+
+func (r *personalAccessTokenRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, revokedAt)
+	return err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type ProfileRepository interface {
+	Create(ctx context.Context, profile *entity.Profile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Profile, error)
+	GetDefaultByUserID(ctx context.Context, userID uuid.UUID) (*entity.Profile, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Profile, error)
+	Update(ctx context.Context, profile *entity.Profile) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type profileRepository struct {
+	db *sqlx.DB
+}
+
+func NewProfileRepository(db *sqlx.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+// Create inserts the profile; creating a second default for the same user is a no-op
+func (r *profileRepository) Create(ctx context.Context, profile *entity.Profile) error {
+	query := `
+		INSERT INTO profiles (id, user_id, name, kind, is_default, timezone, locale,
+		                      morning_time, noon_time, evening_time, night_time, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id) WHERE is_default DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, profile.ID, profile.UserID, profile.Name, profile.Kind, profile.IsDefault,
+		profile.Timezone, profile.Locale, profile.MorningTime, profile.NoonTime, profile.EveningTime, profile.NightTime, profile.CreatedAt)
+	return err
+}
+
+func (r *profileRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Profile, error) {
+	var profile entity.Profile
+	query := `
+		SELECT id, user_id, name, kind, is_default, timezone, locale,
+		       morning_time, noon_time, evening_time, night_time, created_at
+		FROM profiles
+		WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &profile, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *profileRepository) GetDefaultByUserID(ctx context.Context, userID uuid.UUID) (*entity.Profile, error) {
+	var profile entity.Profile
+	query := `
+		SELECT id, user_id, name, kind, is_default, timezone, locale,
+		       morning_time, noon_time, evening_time, night_time, created_at
+		FROM profiles
+		WHERE user_id = $1 AND is_default
+	`
+	err := r.db.GetContext(ctx, &profile, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *profileRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Profile, error) {
+	var profiles []*entity.Profile
+	query := `
+		SELECT id, user_id, name, kind, is_default, timezone, locale,
+		       morning_time, noon_time, evening_time, night_time, created_at
+		FROM profiles
+		WHERE user_id = $1
+		ORDER BY is_default DESC, created_at
+	`
+	if err := r.db.SelectContext(ctx, &profiles, query, userID); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (r *profileRepository) Update(ctx context.Context, profile *entity.Profile) error {
+	query := `
+		UPDATE profiles
+		SET name = $2, kind = $3, timezone = $4, locale = $5,
+		    morning_time = $6, noon_time = $7, evening_time = $8, night_time = $9
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, profile.ID, profile.Name, profile.Kind, profile.Timezone, profile.Locale,
+		profile.MorningTime, profile.NoonTime, profile.EveningTime, profile.NightTime)
+	return err
+}
+
+// Delete removes a non-default profile; its user_medications and logs follow through ON DELETE CASCADE
+func (r *profileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM profiles
+		WHERE id = $1 AND NOT is_default
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"backend/internal/core/entity"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.Session, error)
+	Rotate(ctx context.Context, session *entity.Session, previousHash string) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error)
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, exceptID *uuid.UUID, revokedAt time.Time) error
+}
+
+type sessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, refresh_token_hash, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID, session.UserID, session.RefreshTokenHash, session.DeviceName, session.UserAgent,
+		session.IPAddress, session.CreatedAt, session.LastSeenAt, session.ExpiresAt)
+	return err
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	var session entity.Session
+	query := `
+		SELECT id, user_id, refresh_token_hash, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &session, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.Session, error) {
+	var session entity.Session
+	query := `
+		SELECT id, user_id, refresh_token_hash, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE refresh_token_hash = $1
+	`
+	err := r.db.GetContext(ctx, &session, query, hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate stores the new refresh token hash only if the session still holds previousHash,
+// so two concurrent refreshes with the same token cannot both succeed.
+func (r *sessionRepository) Rotate(ctx context.Context, session *entity.Session, previousHash string) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET refresh_token_hash = $2, ip_address = $3, last_seen_at = $4, expires_at = $5
+		WHERE id = $1 AND refresh_token_hash = $6 AND revoked_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		session.ID, session.RefreshTokenHash, session.IPAddress, session.LastSeenAt, session.ExpiresAt, previousHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	return err
+}
+
+func (r *sessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	query := `
+		SELECT id, user_id, refresh_token_hash, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+	err := r.db.SelectContext(ctx, &sessions, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	query := `
+		SELECT id, user_id, refresh_token_hash, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &sessions, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeAllByUserID revokes every session of the user, keeping exceptID alive when it is set
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, exceptID *uuid.UUID, revokedAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL AND ($3::uuid IS NULL OR id <> $3)
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, revokedAt, exceptID)
+	return err
+}
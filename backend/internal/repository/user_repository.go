@@ -2,8 +2,10 @@ package repository
 
 import (
 	"backend/internal/core/entity"
+	"backend/internal/core/shared"
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -13,6 +15,13 @@ type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+	MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error
+	SetTOTPSecret(ctx context.Context, id uuid.UUID, secret *string) error
+	EnableTOTP(ctx context.Context, id uuid.UUID, enabledAt time.Time) error
+	UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role shared.Role) error
 }
 
 type userRepository struct {
@@ -25,17 +34,17 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	query := `
-		INSERT INTO users (id, email, password, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (id, email, password, role, created_at)
+		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.Password, user.CreatedAt)
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.Password, user.Role, user.CreatedAt)
 	return err
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	var user entity.User
 	query := `
-		SELECT id, email, password, created_at
+		SELECT id, email, password, role, verified_at, totp_secret, totp_enabled_at, totp_last_step, created_at
 		FROM users
 		WHERE id = $1
 	`
@@ -52,7 +61,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
 	query := `
-		SELECT id, email, password, created_at
+		SELECT id, email, password, role, verified_at, totp_secret, totp_enabled_at, totp_last_step, created_at
 		FROM users
 		WHERE email = $1
 	`
@@ -65,3 +74,82 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 	}
 	return &user, nil
 }
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+	query := `
+		UPDATE users
+		SET password = $2
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, hashedPassword)
+	return err
+}
+
+func (r *userRepository) MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET verified_at = $2
+		WHERE id = $1 AND verified_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, verifiedAt)
+	return err
+}
+
+func (r *userRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET email = $2, verified_at = $3
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, email, verifiedAt)
+	return err
+}
+
+// SetTOTPSecret stores a pending secret (or clears it with nil) and disables 2FA until it is confirmed
+func (r *userRepository) SetTOTPSecret(ctx context.Context, id uuid.UUID, secret *string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $2, totp_enabled_at = NULL, totp_last_step = NULL
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, secret)
+	return err
+}
+
+func (r *userRepository) EnableTOTP(ctx context.Context, id uuid.UUID, enabledAt time.Time) error {
+	query := `
+		UPDATE users
+		SET totp_enabled_at = $2
+		WHERE id = $1 AND totp_secret IS NOT NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, id, enabledAt)
+	return err
+}
+
+// UseTOTPStep records the time step of an accepted code and reports false if it was already used
+func (r *userRepository) UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+	result, err := r.db.ExecContext(ctx, query, id, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uuid.UUID, role shared.Role) error {
+	query := `
+		UPDATE users
+		SET role = $2
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, role)
+	return err
+}
@@ -14,7 +14,9 @@ type UserMedicationRepository interface {
 	Create(ctx context.Context, um *entity.UserMedication) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.UserMedication, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserMedication, error)
-	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserMedication, error)
+	GetByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity.UserMedication, error)
+	GetActiveByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity.UserMedication, error)
+	GetActiveByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.UserMedication, error)
 	Update(ctx context.Context, um *entity.UserMedication) error
 }
 
@@ -33,11 +35,11 @@ func (r *userMedicationRepository) Create(ctx context.Context, um *entity.UserMe
 	}
 
 	query := `
-		INSERT INTO user_medications (id, user_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO user_medications (id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	_, err = r.db.ExecContext(ctx, query,
-		um.ID, um.UserID, um.MedicationID, um.BoxesOwned,
+		um.ID, um.UserID, um.ProfileID, um.MedicationID, um.BoxesOwned,
 		schedulesJSON, um.DurationDays, um.StartAt, um.Active, um.CreatedAt)
 	return err
 }
@@ -47,12 +49,12 @@ func (r *userMedicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 	var schedulesJSON []byte
 
 	query := `
-		SELECT id, user_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
+		SELECT id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
 		FROM user_medications
 		WHERE id = $1
 	`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&um.ID, &um.UserID, &um.MedicationID, &um.BoxesOwned,
+		&um.ID, &um.UserID, &um.ProfileID, &um.MedicationID, &um.BoxesOwned,
 		&schedulesJSON, &um.DurationDays, &um.StartAt, &um.Active, &um.CreatedAt)
 
 	if err == sql.ErrNoRows {
@@ -71,7 +73,7 @@ func (r *userMedicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 
 func (r *userMedicationRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserMedication, error) {
 	query := `
-		SELECT id, user_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
+		SELECT id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
 		FROM user_medications
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -85,14 +87,46 @@ func (r *userMedicationRepository) GetByUserID(ctx context.Context, userID uuid.
 	return r.scanUserMedications(rows)
 }
 
-func (r *userMedicationRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.UserMedication, error) {
+func (r *userMedicationRepository) GetByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity.UserMedication, error) {
 	query := `
-		SELECT id, user_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
+		SELECT id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
 		FROM user_medications
-		WHERE user_id = $1 AND active = true
+		WHERE profile_id = $1
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, query, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanUserMedications(rows)
+}
+
+func (r *userMedicationRepository) GetActiveByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity.UserMedication, error) {
+	query := `
+		SELECT id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
+		FROM user_medications
+		WHERE profile_id = $1 AND active = true
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanUserMedications(rows)
+}
+
+func (r *userMedicationRepository) GetActiveByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity.UserMedication, error) {
+	query := `
+		SELECT id, user_id, profile_id, medication_id, boxes_owned, schedules, duration_days, start_at, active, created_at
+		FROM user_medications
+		WHERE medication_id = $1 AND active = true
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, medicationID)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +158,7 @@ func (r *userMedicationRepository) scanUserMedications(rows *sql.Rows) ([]*entit
 		var schedulesJSON []byte
 
 		err := rows.Scan(
-			&um.ID, &um.UserID, &um.MedicationID, &um.BoxesOwned,
+			&um.ID, &um.UserID, &um.ProfileID, &um.MedicationID, &um.BoxesOwned,
 			&schedulesJSON, &um.DurationDays, &um.StartAt, &um.Active, &um.CreatedAt)
 		if err != nil {
 			return nil, err
@@ -1,11 +1,17 @@
 package router
 
 import (
+	"backend/config"
+	"backend/internal/audit"
 	"backend/internal/auth"
+	"backend/internal/core/shared"
 	"backend/internal/db"
 	"backend/internal/handler"
+	"backend/internal/mailer"
+	"backend/internal/oidc"
 	repository2 "backend/internal/repository"
 	service2 "backend/internal/service"
+	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -23,20 +29,65 @@ func SetupRouter() *gin.Engine {
 	medicationRepo := repository2.NewMedicationRepository(database)
 	userMedicationRepo := repository2.NewUserMedicationRepository(database)
 	medicationLogRepo := repository2.NewMedicationLogRepository(database)
+	sessionRepo := repository2.NewSessionRepository(database)
+	passwordResetRepo := repository2.NewPasswordResetRepository(database)
+	accountDeletionRepo := repository2.NewAccountDeletionRepository(database)
+	recoveryCodeRepo := repository2.NewRecoveryCodeRepository(database)
+	loginThrottleRepo := repository2.NewLoginThrottleRepository(database)
+	careGrantRepo := repository2.NewCareGrantRepository(database)
+	profileRepo := repository2.NewProfileRepository(database)
+	personalAccessTokenRepo := repository2.NewPersonalAccessTokenRepository(database)
+	oidcStateRepo := repository2.NewOIDCStateRepository(database)
+	userIdentityRepo := repository2.NewUserIdentityRepository(database)
+	auditEventRepo := repository2.NewAuditEventRepository(database)
+	ingredientRepo := repository2.NewIngredientRepository(database)
+	attachmentRepo := repository2.NewAttachmentRepository(database)
 
-	userService := service2.NewUserService(userRepo)
-	authService := service2.NewAuthService(userRepo)
-	medicationService := service2.NewMedicationService(medicationRepo)
-	medicationLogService := service2.NewMedicationLogService(medicationLogRepo)
-	userMedicationService := service2.NewUserMedicationService(userMedicationRepo, medicationService, medicationLogService)
+	mail := mailer.New()
+	files := storage.New()
 
-	authHandler := handler.NewAuthHandler(authService, userService)
+	userService := service2.NewUserService(userRepo, sessionRepo, personalAccessTokenRepo, profileRepo, mail)
+	twoFactorService := service2.NewTwoFactorService(userRepo, recoveryCodeRepo)
+	loginThrottleService := service2.NewLoginThrottleService(loginThrottleRepo, mail)
+	authService := service2.NewAuthService(userRepo, sessionRepo, twoFactorService, loginThrottleService)
+	sessionService := service2.NewSessionService(sessionRepo)
+	oidcService := service2.NewOIDCService(oidc.NewRegistry(config.OIDCProviders), oidcStateRepo, userIdentityRepo, userRepo, profileRepo, authService)
+	personalAccessTokenService := service2.NewPersonalAccessTokenService(personalAccessTokenRepo, userRepo)
+	passwordResetService := service2.NewPasswordResetService(userRepo, passwordResetRepo, sessionRepo, personalAccessTokenRepo, mail)
+	emailVerificationService := service2.NewEmailVerificationService(userRepo, mail)
+	auditService := service2.NewAuditService(auditEventRepo)
+	attachmentService := service2.NewAttachmentService(attachmentRepo, files, auditService)
+	accountService := service2.NewAccountService(userRepo, sessionRepo, medicationRepo, userMedicationRepo, medicationLogRepo, accountDeletionRepo, careGrantRepo, profileRepo, mail, attachmentService, auditService)
+	interactionService := service2.NewInteractionService(ingredientRepo, medicationRepo)
+	medicationService := service2.NewMedicationService(medicationRepo, userMedicationRepo, interactionService, auditService)
+	medicationImportService := service2.NewMedicationImportService(medicationRepo, auditService)
+	medicationLogService := service2.NewMedicationLogService(medicationLogRepo, userMedicationRepo, profileRepo, auditService)
+	profileService := service2.NewProfileService(profileRepo, userRepo)
+	userMedicationService := service2.NewUserMedicationService(userMedicationRepo, medicationService, medicationLogService, profileService, auditService, interactionService)
+	careGrantService := service2.NewCareGrantService(careGrantRepo, userRepo, mail)
+
+	policy := auth.NewPolicy(careGrantService)
+
+	authHandler := handler.NewAuthHandler(authService, userService, passwordResetService, emailVerificationService)
 	userHandler := handler.NewUserHandler(userService)
-	medicationHandler := handler.NewMedicationHandler(medicationService)
-	userMedicationHandler := handler.NewUserMedicationHandler(userMedicationService)
-	medicationLogHandler := handler.NewMedicationLogHandler(medicationLogService, userMedicationService)
+	sessionHandler := handler.NewSessionHandler(sessionService, policy)
+	accountHandler := handler.NewAccountHandler(accountService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	medicationHandler := handler.NewMedicationHandler(medicationService, attachmentService)
+	userMedicationHandler := handler.NewUserMedicationHandler(userMedicationService, profileService, policy)
+	medicationLogHandler := handler.NewMedicationLogHandler(medicationLogService, userMedicationService, policy)
+	careGrantHandler := handler.NewCareGrantHandler(careGrantService)
+	profileHandler := handler.NewProfileHandler(profileService, policy)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	wellKnownHandler := handler.NewWellKnownHandler()
+	auditHandler := handler.NewAuditHandler(auditService)
+	medicationImportHandler := handler.NewMedicationImportHandler(medicationImportService)
+	interactionHandler := handler.NewInteractionHandler(interactionService, medicationService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, medicationService)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
 
 	api := router.Group("/api")
 	{
@@ -44,31 +95,128 @@ func SetupRouter() *gin.Engine {
 		{
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/2fa/verify", authHandler.VerifyTwoFactor)
+			authGroup.GET("/unlock", authHandler.UnlockAccount)
+			authGroup.POST("/refresh", authHandler.Refresh)
+			authGroup.POST("/logout", authHandler.Logout)
+			authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+			authGroup.POST("/reset-password", authHandler.ResetPassword)
+			authGroup.GET("/verify-email", authHandler.VerifyEmail)
+			authGroup.POST("/resend-verification", authHandler.ResendVerification)
+			authGroup.GET("/confirm-email-change", userHandler.ConfirmEmailChange)
+			authGroup.GET("/oidc/providers", oidcHandler.Providers)
+			authGroup.GET("/oidc/:provider/authorize", oidcHandler.Authorize)
+			authGroup.GET("/oidc/:provider/callback", oidcHandler.Callback)
 		}
 
 		protectedGroup := api.Group("")
-		protectedGroup.Use(auth.AuthMiddleware())
+		protectedGroup.Use(auth.AuthMiddleware(sessionService, personalAccessTokenService), audit.Middleware())
 		{
-			protectedGroup.GET("/me", userHandler.GetMe)
+			protectedGroup.GET("/me/export", auth.RequireScope(auth.ScopeAccountExport), accountHandler.Export)
+
+			// Account and security endpoints are only reachable with a login session, never with a personal access token
+			accountGroup := protectedGroup.Group("")
+			accountGroup.Use(auth.RequireSession())
+			{
+				accountGroup.GET("/me", userHandler.GetMe)
+				accountGroup.PUT("/me/password", userHandler.ChangePassword)
+				accountGroup.PUT("/me/email", userHandler.ChangeEmail)
+				accountGroup.POST("/me/deletion", accountHandler.RequestDeletion)
+				accountGroup.GET("/me/deletion", accountHandler.GetDeletion)
+				accountGroup.DELETE("/me/deletion", accountHandler.CancelDeletion)
+				accountGroup.GET("/me/audit-events", auditHandler.ListMine)
+
+				twoFactorGroup := accountGroup.Group("/me/2fa")
+				{
+					twoFactorGroup.POST("/enroll", twoFactorHandler.Enroll)
+					twoFactorGroup.POST("/confirm", twoFactorHandler.Confirm)
+					twoFactorGroup.POST("/disable", twoFactorHandler.Disable)
+				}
+
+				accountGroup.POST("/me/caregivers", careGrantHandler.Invite)
+				accountGroup.GET("/me/caregivers", careGrantHandler.ListCaregivers)
+				accountGroup.GET("/me/patients", careGrantHandler.ListPatients)
+
+				careGrantGroup := accountGroup.Group("/care-grants")
+				{
+					careGrantGroup.POST("/accept", careGrantHandler.Accept)
+					careGrantGroup.DELETE("/:id", careGrantHandler.Revoke)
+				}
+
+				sessionGroup := accountGroup.Group("/me/sessions")
+				{
+					sessionGroup.GET("", sessionHandler.List)
+					sessionGroup.DELETE("", sessionHandler.RevokeOthers)
+					sessionGroup.DELETE("/:id", sessionHandler.Revoke)
+				}
+
+				tokenGroup := accountGroup.Group("/me/tokens")
+				{
+					tokenGroup.GET("", personalAccessTokenHandler.List)
+					tokenGroup.POST("", personalAccessTokenHandler.Create)
+					tokenGroup.DELETE("/:id", personalAccessTokenHandler.Revoke)
+				}
+
+				adminGroup := accountGroup.Group("/admin")
+				adminGroup.Use(auth.RequireRole(shared.RoleAdmin))
+				{
+					adminGroup.PUT("/users/:id/role", userHandler.UpdateRole)
+					adminGroup.GET("/audit-events", auditHandler.List)
+					adminGroup.POST("/medications/import", medicationImportHandler.Import)
+					adminGroup.GET("/medications/:id/duplicates", medicationHandler.Duplicates)
+					adminGroup.POST("/medications/:id/merge", medicationHandler.Merge)
+					adminGroup.POST("/interactions/import", interactionHandler.Import)
+				}
+			}
+
+			profileGroup := protectedGroup.Group("/me/profiles")
+			profileGroup.Use(auth.RequireResourceScope("profiles"))
+			{
+				profileGroup.GET("", profileHandler.List)
+				profileGroup.POST("", profileHandler.Create)
+				profileGroup.PUT("/:id", profileHandler.Update)
+				profileGroup.DELETE("/:id", profileHandler.Delete)
+			}
 
 			medicationGroup := protectedGroup.Group("/medications")
+			medicationGroup.Use(auth.RequireResourceScope("catalog"))
 			{
 				medicationGroup.POST("", medicationHandler.Create)
 				medicationGroup.GET("", medicationHandler.List)
+				medicationGroup.GET("/by-barcode/:code", medicationHandler.GetByBarcode)
 				medicationGroup.GET("/:id", medicationHandler.GetByID)
 				medicationGroup.PUT("/:id", medicationHandler.Update)
+				medicationGroup.DELETE("/:id", medicationHandler.Delete)
+				medicationGroup.POST("/:id/archive", medicationHandler.Archive)
+				medicationGroup.POST("/:id/restore", medicationHandler.Restore)
+				medicationGroup.GET("/:id/ingredients", interactionHandler.ListIngredients)
+				medicationGroup.PUT("/:id/ingredients", interactionHandler.SetIngredients)
+				medicationGroup.POST("/:id/attachments", attachmentHandler.Upload)
+				medicationGroup.GET("/:id/attachments", attachmentHandler.List)
+				medicationGroup.GET("/:id/attachments/:attachmentId", attachmentHandler.Download)
+				medicationGroup.DELETE("/:id/attachments/:attachmentId", attachmentHandler.Delete)
+			}
+
+			reviewGroup := protectedGroup.Group("/medications")
+			reviewGroup.Use(auth.RequireResourceScope("catalog"), auth.RequireRole(shared.RolePharmacist, shared.RoleAdmin))
+			{
+				reviewGroup.GET("/pending", medicationHandler.ListPending)
+				reviewGroup.POST("/:id/approve", medicationHandler.Approve)
+				reviewGroup.POST("/:id/reject", medicationHandler.Reject)
 			}
 
 			userMedicationGroup := protectedGroup.Group("/user-medications")
+			userMedicationGroup.Use(auth.RequireResourceScope("medications"))
 			{
 				userMedicationGroup.POST("", userMedicationHandler.Create)
-				userMedicationGroup.GET("", userMedicationHandler.GetByUserID)
-				userMedicationGroup.GET("/active", userMedicationHandler.GetActiveByUserID)
+				userMedicationGroup.GET("", userMedicationHandler.GetByProfileID)
+				userMedicationGroup.GET("/active", userMedicationHandler.GetActiveByProfileID)
 				userMedicationGroup.PUT("/:id", userMedicationHandler.Update)
 				userMedicationGroup.GET("/:id/stats", userMedicationHandler.GetStats)
 			}
 
 			medicationLogGroup := protectedGroup.Group("/medication-logs")
+			medicationLogGroup.Use(auth.RequireResourceScope("logs"))
 			{
 				medicationLogGroup.PUT("/:id/mark-taken", medicationLogHandler.MarkAsTaken)
 				medicationLogGroup.GET("/user-medication/:user_medication_id", medicationLogHandler.GetByUserMedicationID)
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/mailer"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// deletionBatchSize bounds how many accounts a single ProcessDueDeletions run removes
+const deletionBatchSize = 100
+
+type AccountService struct {
+	userRepo            UserRepository
+	sessionRepo         SessionRepository
+	medicationRepo      MedicationRepository
+	userMedicationRepo  UserMedicationRepository
+	medicationLogRepo   MedicationLogRepository
+	accountDeletionRepo AccountDeletionRepository
+	careGrantRepo       CareGrantRepository
+	profileRepo         ProfileRepository
+	mailer              mailer.Mailer
+	attachmentService   *AttachmentService
+	auditService        *AuditService
+}
+
+func NewAccountService(userRepo UserRepository, sessionRepo SessionRepository, medicationRepo MedicationRepository, userMedicationRepo UserMedicationRepository, medicationLogRepo MedicationLogRepository, accountDeletionRepo AccountDeletionRepository, careGrantRepo CareGrantRepository, profileRepo ProfileRepository, mailer mailer.Mailer, attachmentService *AttachmentService, auditService *AuditService) *AccountService {
+	return &AccountService{
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		medicationRepo:      medicationRepo,
+		userMedicationRepo:  userMedicationRepo,
+		medicationLogRepo:   medicationLogRepo,
+		accountDeletionRepo: accountDeletionRepo,
+		careGrantRepo:       careGrantRepo,
+		profileRepo:         profileRepo,
+		mailer:              mailer,
+		attachmentService:   attachmentService,
+		auditService:        auditService,
+	}
+}
+
+// Export collects every record that belongs to the user
+func (s *AccountService) Export(ctx context.Context, userID uuid.UUID) (*dto.DataExportResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	sessions, err := s.sessionRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	profiles, err := s.profileRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	userMedications, err := s.userMedicationRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user medications: %w", err)
+	}
+
+	deletionRequests, err := s.accountDeletionRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account deletion requests: %w", err)
+	}
+
+	issuedGrants, err := s.careGrantRepo.ListByPatientID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care grants: %w", err)
+	}
+	receivedGrants, err := s.careGrantRepo.ListActiveByCaregiverID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care grants: %w", err)
+	}
+
+	auditEvents, err := s.auditService.ExportForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dto.DataExportResponse{
+		ExportedAt:       time.Now(),
+		User:             *mapper.UserFromEntity(user),
+		Sessions:         make([]*dto.SessionResponse, len(sessions)),
+		Profiles:         make([]*dto.ProfileResponse, len(profiles)),
+		Medications:      []*dto.MedicationResponse{},
+		UserMedications:  make([]*dto.UserMedicationResponse, len(userMedications)),
+		MedicationLogs:   []*dto.MedicationLogResponse{},
+		DeletionRequests: make([]*dto.AccountDeletionResponse, len(deletionRequests)),
+		CareGrants:       []*dto.CareGrantResponse{},
+		AuditEvents:      auditEvents,
+	}
+
+	for i, session := range sessions {
+		export.Sessions[i] = mapper.SessionFromEntity(session, uuid.Nil)
+	}
+	for i, profile := range profiles {
+		export.Profiles[i] = mapper.ProfileFromEntity(profile)
+	}
+	for i, req := range deletionRequests {
+		export.DeletionRequests[i] = mapper.AccountDeletionFromEntity(req)
+	}
+	for _, grant := range append(issuedGrants, receivedGrants...) {
+		export.CareGrants = append(export.CareGrants, mapper.CareGrantFromEntity(grant, export.ExportedAt))
+	}
+
+	seenMedications := make(map[uuid.UUID]bool)
+	for i, um := range userMedications {
+		export.UserMedications[i] = mapper.UserMedicationFromEntity(um)
+
+		if !seenMedications[um.MedicationID] {
+			seenMedications[um.MedicationID] = true
+			medication, err := s.medicationRepo.GetByID(ctx, um.MedicationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get medication: %w", err)
+			}
+			if medication != nil {
+				export.Medications = append(export.Medications, mapper.MedicationFromEntity(medication))
+			}
+		}
+
+		logs, err := s.medicationLogRepo.GetByUserMedicationID(ctx, um.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication logs: %w", err)
+		}
+		for _, l := range logs {
+			export.MedicationLogs = append(export.MedicationLogs, mapper.MedicationLogFromEntity(l))
+		}
+	}
+
+	return export, nil
+}
+
+// RequestDeletion schedules the account for deletion once the grace period has passed
+func (s *AccountService) RequestDeletion(ctx context.Context, userID uuid.UUID, req *dto.DeleteAccountRequest, ipAddress string) (*dto.AccountDeletionResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, fmt.Errorf("password is incorrect")
+	}
+
+	pending, err := s.accountDeletionRepo.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account deletion request: %w", err)
+	}
+	if pending != nil {
+		return mapper.AccountDeletionFromEntity(pending), nil
+	}
+
+	now := time.Now()
+	deletion := &entity2.AccountDeletionRequest{
+		ID:           uuid.New(),
+		UserID:       userID,
+		RequestedIP:  optionalString(ipAddress),
+		RequestedAt:  now,
+		ScheduledFor: now.Add(config.AccountDeletionGracePeriod),
+	}
+
+	if err := s.accountDeletionRepo.Create(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to create account deletion request: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your DoseLog account is scheduled for deletion",
+		Body: fmt.Sprintf("Your DoseLog account and all of its medication data will be permanently deleted on %s.\n\n"+
+			"If you change your mind, log in and cancel the deletion before then.", deletion.ScheduledFor.Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send deletion notice to user %s: %v", userID, err)
+	}
+
+	return mapper.AccountDeletionFromEntity(deletion), nil
+}
+
+func (s *AccountService) GetPendingDeletion(ctx context.Context, userID uuid.UUID) (*dto.AccountDeletionResponse, error) {
+	pending, err := s.accountDeletionRepo.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account deletion request: %w", err)
+	}
+	if pending == nil {
+		return nil, nil
+	}
+	return mapper.AccountDeletionFromEntity(pending), nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	pending, err := s.accountDeletionRepo.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get account deletion request: %w", err)
+	}
+	if pending == nil {
+		return fmt.Errorf("no pending account deletion")
+	}
+
+	if err := s.accountDeletionRepo.Cancel(ctx, pending.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessDueDeletions removes every account whose grace period has ended
+func (s *AccountService) ProcessDueDeletions(ctx context.Context) error {
+	for {
+		completed, err := s.accountDeletionRepo.CompleteDue(ctx, time.Now(), deletionBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to complete account deletions: %w", err)
+		}
+		for _, req := range completed {
+			s.attachmentService.RemoveFiles(ctx, req.AttachmentFiles...)
+			log.Printf("account deletion %s completed for user %s", req.ID, req.UserID)
+		}
+		if len(completed) < deletionBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"backend/internal/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxAttachmentFileNameLength = 255
+	// maxAttachmentPixels guards against small files that decode to huge images
+	maxAttachmentPixels = 40_000_000
+	thumbnailSize       = 256
+	thumbnailQuality    = 80
+)
+
+// attachmentKinds maps the accepted content types, as sniffed from the file, to their kind
+var attachmentKinds = map[string]shared.AttachmentKind{
+	"image/jpeg":      shared.AttachmentImage,
+	"image/png":       shared.AttachmentImage,
+	"application/pdf": shared.AttachmentLeaflet,
+}
+
+// AttachmentTooLargeError is returned when an upload exceeds the size limit of its kind
+type AttachmentTooLargeError struct {
+	Kind  shared.AttachmentKind
+	Limit int
+}
+
+func (e *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("%s attachments may not be larger than %d bytes", e.Kind, e.Limit)
+}
+
+// AttachmentValidationError is returned when an upload is empty, of an unsupported type or an
+// image that cannot be decoded or has unsupported dimensions
+type AttachmentValidationError struct {
+	Reason string
+}
+
+func (e *AttachmentValidationError) Error() string {
+	return e.Reason
+}
+
+// MaxAttachmentSize is the largest upload any kind accepts
+func MaxAttachmentSize() int {
+	return max(config.AttachmentMaxImageBytes, config.AttachmentMaxDocumentBytes)
+}
+
+type AttachmentService struct {
+	attachmentRepo AttachmentRepository
+	storage        storage.Storage
+	auditService   *AuditService
+}
+
+func NewAttachmentService(attachmentRepo AttachmentRepository, storage storage.Storage, auditService *AuditService) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+		auditService:   auditService,
+	}
+}
+
+// Upload validates the file by its content, stores it together with a thumbnail for images and records it
+func (s *AttachmentService) Upload(ctx context.Context, medicationID, userID uuid.UUID, fileName string, data []byte) (*dto.MedicationAttachmentResponse, error) {
+	if len(data) == 0 {
+		return nil, &AttachmentValidationError{Reason: "file is empty"}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	kind, ok := attachmentKinds[contentType]
+	if !ok {
+		return nil, &AttachmentValidationError{Reason: fmt.Sprintf("unsupported file type %s: upload a JPEG or PNG image or a PDF leaflet", contentType)}
+	}
+
+	limit := config.AttachmentMaxDocumentBytes
+	if kind == shared.AttachmentImage {
+		limit = config.AttachmentMaxImageBytes
+	}
+	if len(data) > limit {
+		return nil, &AttachmentTooLargeError{Kind: kind, Limit: limit}
+	}
+
+	var thumbnail []byte
+	if kind == shared.AttachmentImage {
+		var err error
+		if thumbnail, err = makeThumbnail(data); err != nil {
+			return nil, err
+		}
+	}
+
+	attachment := &entity2.MedicationAttachment{
+		ID:           uuid.New(),
+		MedicationID: medicationID,
+		Kind:         kind,
+		FileName:     cleanFileName(fileName, kind),
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		UploadedBy:   &userID,
+		CreatedAt:    time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("medications/%s/%s", medicationID, attachment.ID)
+
+	if err := s.storage.Put(ctx, attachment.StorageKey, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if thumbnail != nil {
+		thumbnailKey := attachment.StorageKey + "-thumb"
+		attachment.ThumbnailKey = &thumbnailKey
+		if err := s.storage.Put(ctx, thumbnailKey, thumbnail, "image/jpeg"); err != nil {
+			s.RemoveFiles(ctx, attachment.StorageKey)
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+	}
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		s.RemoveFiles(ctx, attachmentKeys(attachment)...)
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if err := s.auditService.Record(ctx, shared.AuditCreate, shared.AuditEntityMedicationAttachment, attachment.ID, nil, nil, attachment); err != nil {
+		return nil, err
+	}
+
+	return mapper.MedicationAttachmentFromEntity(attachment), nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, medicationID uuid.UUID) ([]*dto.MedicationAttachmentResponse, error) {
+	attachments, err := s.attachmentRepo.ListByMedicationID(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	responses := make([]*dto.MedicationAttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = mapper.MedicationAttachmentFromEntity(attachment)
+	}
+	return responses, nil
+}
+
+// Open returns the attachment and a reader for its file, or its thumbnail when asked for. It returns
+// nil when the medication has no such attachment or, for thumbnail, when the attachment has none.
+// The caller closes the reader.
+func (s *AttachmentService) Open(ctx context.Context, medicationID, attachmentID uuid.UUID, thumbnail bool) (*dto.MedicationAttachmentResponse, io.ReadCloser, error) {
+	attachment, err := s.get(ctx, medicationID, attachmentID)
+	if err != nil || attachment == nil {
+		return nil, nil, err
+	}
+
+	key := attachment.StorageKey
+	response := mapper.MedicationAttachmentFromEntity(attachment)
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			return nil, nil, nil
+		}
+		key = *attachment.ThumbnailKey
+		response.ContentType = "image/jpeg"
+	}
+
+	reader, err := s.storage.Get(ctx, key)
+	if err == storage.ErrNotFound {
+		return nil, nil, fmt.Errorf("attachment file %s is missing from storage", key)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return response, reader, nil
+}
+
+// Delete removes the attachment and its files; it reports false when the medication has no such attachment
+func (s *AttachmentService) Delete(ctx context.Context, medicationID, attachmentID uuid.UUID) (bool, error) {
+	attachment, err := s.get(ctx, medicationID, attachmentID)
+	if err != nil || attachment == nil {
+		return false, err
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		return false, fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	s.RemoveFiles(ctx, attachmentKeys(attachment)...)
+	if err := s.auditService.Record(ctx, shared.AuditDelete, shared.AuditEntityMedicationAttachment, attachment.ID, nil, attachment, nil); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// StorageKeys lists the files of every attachment of a medication. Deleting the medication cascades
+// to the rows, so callers collect the keys first and pass them to RemoveFiles once it is gone.
+func (s *AttachmentService) StorageKeys(ctx context.Context, medicationID uuid.UUID) ([]string, error) {
+	attachments, err := s.attachmentRepo.ListByMedicationID(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	var keys []string
+	for _, attachment := range attachments {
+		keys = append(keys, attachmentKeys(attachment)...)
+	}
+	return keys, nil
+}
+
+// RemoveFiles deletes stored files; failures only leave orphaned files behind, so they are logged
+func (s *AttachmentService) RemoveFiles(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete attachment file %s: %v", key, err)
+		}
+	}
+}
+
+// get loads an attachment, treating one of another medication as missing
+func (s *AttachmentService) get(ctx context.Context, medicationID, attachmentID uuid.UUID) (*entity2.MedicationAttachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment == nil || attachment.MedicationID != medicationID {
+		return nil, nil
+	}
+	return attachment, nil
+}
+
+func attachmentKeys(attachment *entity2.MedicationAttachment) []string {
+	keys := []string{attachment.StorageKey}
+	if attachment.ThumbnailKey != nil {
+		keys = append(keys, *attachment.ThumbnailKey)
+	}
+	return keys
+}
+
+// cleanFileName keeps the base name of an uploaded file without control characters, falling back
+// to a name for the kind
+func cleanFileName(name string, kind shared.AttachmentKind) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == ".." || name == "/" {
+		if kind == shared.AttachmentImage {
+			return "image"
+		}
+		return "leaflet.pdf"
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentFileNameLength {
+		name = string(runes[len(runes)-maxAttachmentFileNameLength:])
+	}
+	return name
+}
+
+// makeThumbnail decodes an image and encodes a JPEG copy that fits in thumbnailSize pixels,
+// averaging the source pixels each thumbnail pixel covers
+func makeThumbnail(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &AttachmentValidationError{Reason: fmt.Sprintf("invalid image: %v", err)}
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxAttachmentPixels {
+		return nil, &AttachmentValidationError{Reason: fmt.Sprintf("image dimensions %dx%d are not supported", cfg.Width, cfg.Height)}
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &AttachmentValidationError{Reason: fmt.Sprintf("invalid image: %v", err)}
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			width, height = thumbnailSize, max(1, height*thumbnailSize/bounds.Dx())
+		} else {
+			width, height = max(1, width*thumbnailSize/bounds.Dy()), thumbnailSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			// JPEG has no alpha: composite transparent pixels onto white
+			white := 0xffff*n - a
+			dst.Set(x, y, color.RGBA64{
+				R: uint16((r + white) / n),
+				G: uint16((g + white) / n),
+				B: uint16((b + white) / n),
+				A: 0xffff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
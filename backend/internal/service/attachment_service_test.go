@@ -0,0 +1,301 @@
+package service
+
+import (
+	"backend/config"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/shared"
+	"backend/internal/storage"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryAttachmentRepository keeps attachments in a map
+type memoryAttachmentRepository struct {
+	attachments map[uuid.UUID]*entity2.MedicationAttachment
+}
+
+func (r *memoryAttachmentRepository) Create(ctx context.Context, attachment *entity2.MedicationAttachment) error {
+	r.attachments[attachment.ID] = attachment
+	return nil
+}
+
+func (r *memoryAttachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity2.MedicationAttachment, error) {
+	return r.attachments[id], nil
+}
+
+func (r *memoryAttachmentRepository) ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity2.MedicationAttachment, error) {
+	var attachments []*entity2.MedicationAttachment
+	for _, attachment := range r.attachments {
+		if attachment.MedicationID == medicationID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *memoryAttachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.attachments, id)
+	return nil
+}
+
+// discardAuditRepository drops every event
+type discardAuditRepository struct{}
+
+func (discardAuditRepository) Create(ctx context.Context, event *entity2.AuditEvent) error {
+	return nil
+}
+
+func (discardAuditRepository) List(ctx context.Context, filter entity2.AuditEventFilter) ([]*entity2.AuditEvent, error) {
+	return nil, nil
+}
+
+func (discardAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestAttachmentService(t *testing.T) (*AttachmentService, *memoryAttachmentRepository) {
+	t.Helper()
+	repo := &memoryAttachmentRepository{attachments: make(map[uuid.UUID]*entity2.MedicationAttachment)}
+	return NewAttachmentService(repo, storage.NewLocalStorage(t.TempDir()), NewAuditService(discardAuditRepository{})), repo
+}
+
+// withAttachmentLimits sets the size limits for the duration of the test
+func withAttachmentLimits(t *testing.T, imageBytes, documentBytes int) {
+	t.Helper()
+	previousImage, previousDocument := config.AttachmentMaxImageBytes, config.AttachmentMaxDocumentBytes
+	config.AttachmentMaxImageBytes, config.AttachmentMaxDocumentBytes = imageBytes, documentBytes
+	t.Cleanup(func() {
+		config.AttachmentMaxImageBytes, config.AttachmentMaxDocumentBytes = previousImage, previousDocument
+	})
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func filledImage(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestUploadSizeLimits(t *testing.T) {
+	withAttachmentLimits(t, 1024, 2048)
+	smallPNG := encodePNG(t, filledImage(2, 2, color.White))
+	pdf := func(size int) []byte {
+		return append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte{' '}, size-9)...)
+	}
+	pngOfSize := func(size int) []byte {
+		return append(append([]byte{}, smallPNG...), make([]byte, size-len(smallPNG))...)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		wantLimit int
+		wantKind  shared.AttachmentKind
+	}{
+		{name: "image at the limit", data: pngOfSize(1024), wantKind: shared.AttachmentImage},
+		{name: "image over the limit", data: pngOfSize(1025), wantLimit: 1024, wantKind: shared.AttachmentImage},
+		{name: "leaflet over the image limit", data: pdf(2048), wantKind: shared.AttachmentLeaflet},
+		{name: "leaflet over the limit", data: pdf(2049), wantLimit: 2048, wantKind: shared.AttachmentLeaflet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestAttachmentService(t)
+
+			got, err := s.Upload(context.Background(), uuid.New(), uuid.New(), "upload", tt.data)
+			if tt.wantLimit != 0 {
+				var tooLarge *AttachmentTooLargeError
+				if !errors.As(err, &tooLarge) || tooLarge.Limit != tt.wantLimit || tooLarge.Kind != tt.wantKind {
+					t.Fatalf("Upload() error = %v, want the %s limit of %d bytes", err, tt.wantKind, tt.wantLimit)
+				}
+				if len(repo.attachments) != 0 {
+					t.Errorf("Upload() recorded a refused attachment")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+			if got.Kind != tt.wantKind || got.SizeBytes != int64(len(tt.data)) {
+				t.Errorf("Upload() = %+v, want a %s of %d bytes", got, tt.wantKind, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestUploadRejectsUnsupportedContent(t *testing.T) {
+	withAttachmentLimits(t, 1024, 2048)
+	s, _ := newTestAttachmentService(t)
+
+	// The type is checked before the size, so an oversized file of the wrong type is not reported as too large
+	for _, data := range [][]byte{nil, []byte("plain text"), bytes.Repeat([]byte("GIF89a"), 1000)} {
+		_, err := s.Upload(context.Background(), uuid.New(), uuid.New(), "upload", data)
+		var invalid *AttachmentValidationError
+		if !errors.As(err, &invalid) {
+			t.Errorf("Upload(%d bytes) error = %v, want a validation error", len(data), err)
+		}
+	}
+}
+
+func TestUploadStoresThumbnail(t *testing.T) {
+	withAttachmentLimits(t, 1<<20, 1<<20)
+	s, repo := newTestAttachmentService(t)
+	medicationID := uuid.New()
+
+	got, err := s.Upload(context.Background(), medicationID, uuid.New(), "box.png", encodePNG(t, filledImage(600, 300, color.White)))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got.FileName != "box.png" || got.ContentType != "image/png" {
+		t.Errorf("Upload() = %+v, want box.png as image/png", got)
+	}
+
+	attachment, reader, err := s.Open(context.Background(), medicationID, got.ID, true)
+	if err != nil || attachment == nil {
+		t.Fatalf("Open() = %v, %v, want the thumbnail", attachment, err)
+	}
+	defer reader.Close()
+	thumbnail, err := jpeg.DecodeConfig(reader)
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if thumbnail.Width != 256 || thumbnail.Height != 128 {
+		t.Errorf("thumbnail is %dx%d, want 256x128", thumbnail.Width, thumbnail.Height)
+	}
+	if stored := repo.attachments[got.ID]; stored.ThumbnailKey == nil {
+		t.Error("Upload() did not record the thumbnail key")
+	}
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{name: "landscape", width: 1024, height: 512, wantWidth: 256, wantHeight: 128},
+		{name: "portrait", width: 300, height: 900, wantWidth: 85, wantHeight: 256},
+		{name: "small image keeps its size", width: 100, height: 50, wantWidth: 100, wantHeight: 50},
+		{name: "thin strip keeps one pixel", width: 2000, height: 2, wantWidth: 256, wantHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := makeThumbnail(encodePNG(t, filledImage(tt.width, tt.height, color.Black)))
+			if err != nil {
+				t.Fatalf("makeThumbnail() error = %v", err)
+			}
+			img, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("thumbnail is not a JPEG: %v", err)
+			}
+			if got := img.Bounds(); got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Errorf("thumbnail is %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestMakeThumbnailCompositesTransparencyOnWhite(t *testing.T) {
+	data, err := makeThumbnail(encodePNG(t, filledImage(10, 10, color.NRGBA{})))
+	if err != nil {
+		t.Fatalf("makeThumbnail() error = %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if r, g, b, _ := img.At(5, 5).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("transparent pixel became %d,%d,%d, want white", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestMakeThumbnailRejectsInvalidImages(t *testing.T) {
+	// A PNG header claiming 100000x100000 pixels must be refused before it is decoded
+	huge := encodePNG(t, filledImage(1, 1, color.White))
+	binary.BigEndian.PutUint32(huge[16:20], 100000)
+	binary.BigEndian.PutUint32(huge[20:24], 100000)
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "garbage", data: []byte("not an image"), wantErr: "invalid image"},
+		{name: "too many pixels", data: huge, wantErr: "100000x100000 are not supported"},
+	}
+
+	for _, tt := range tests {
+		_, err := makeThumbnail(tt.data)
+		var invalid *AttachmentValidationError
+		if !errors.As(err, &invalid) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("makeThumbnail(%s) error = %v, want a validation error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCleanFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		kind shared.AttachmentKind
+		want string
+	}{
+		{name: "box.jpg", kind: shared.AttachmentImage, want: "box.jpg"},
+		{name: "../../etc/passwd", kind: shared.AttachmentLeaflet, want: "passwd"},
+		{name: `C:\Users\me\leaflet.pdf`, kind: shared.AttachmentLeaflet, want: "leaflet.pdf"},
+		{name: "bad\"name\n.pdf", kind: shared.AttachmentLeaflet, want: "badname.pdf"},
+		{name: "../", kind: shared.AttachmentLeaflet, want: "leaflet.pdf"},
+		{name: "", kind: shared.AttachmentImage, want: "image"},
+		{name: strings.Repeat("a", 300) + ".pdf", kind: shared.AttachmentLeaflet, want: strings.Repeat("a", 251) + ".pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanFileName(tt.name, tt.kind); got != tt.want {
+			t.Errorf("cleanFileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// failingAuditRepository refuses every event
+type failingAuditRepository struct {
+	discardAuditRepository
+}
+
+func (failingAuditRepository) Create(ctx context.Context, event *entity2.AuditEvent) error {
+	return errors.New("audit_events is unavailable")
+}
+
+func TestUploadFailsWhenTheAuditEventIsNotWritten(t *testing.T) {
+	withAttachmentLimits(t, 1<<20, 1<<20)
+	repo := &memoryAttachmentRepository{attachments: make(map[uuid.UUID]*entity2.MedicationAttachment)}
+	s := NewAttachmentService(repo, storage.NewLocalStorage(t.TempDir()), NewAuditService(failingAuditRepository{}))
+
+	if _, err := s.Upload(context.Background(), uuid.New(), uuid.New(), "box.png", encodePNG(t, filledImage(2, 2, color.White))); err == nil {
+		t.Error("Upload() succeeded without its audit event")
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/audit"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+type AuditService struct {
+	auditRepo AuditEventRepository
+}
+
+func NewAuditService(auditRepo AuditEventRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends an audit event for a mutation that already happened. before and after are
+// entities (nil for creates and deletes respectively); subjectUserID is the account whose data
+// changed, nil for shared catalog rows. The actor comes from the request context.
+//
+// The event is written after the mutation commits, outside its transaction. Callers must fail
+// the request when Record fails, so a change never succeeds silently without its audit event.
+func (s *AuditService) Record(ctx context.Context, action shared.AuditAction, entityType shared.AuditEntity, entityID uuid.UUID, subjectUserID *uuid.UUID, before, after interface{}) error {
+	actor := audit.ActorFrom(ctx)
+
+	event := &entity2.AuditEvent{
+		ID:            uuid.New(),
+		OccurredAt:    time.Now(),
+		ActorID:       actor.UserID,
+		SubjectUserID: subjectUserID,
+		SessionID:     actor.SessionID,
+		TokenID:       actor.TokenID,
+		IPAddress:     optionalString(actor.IPAddress),
+		UserAgent:     optionalString(actor.UserAgent),
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityID,
+	}
+
+	if err := encodeAuditDocuments(event, audit.Snapshot(before), audit.Snapshot(after)); err != nil {
+		return fmt.Errorf("failed to encode audit event for %s %s %s: %w", action, entityType, entityID, err)
+	}
+
+	// The request may already be cancelled, but the event must still be written
+	if err := s.auditRepo.Create(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("audit: failed to record %s %s %s: %v", action, entityType, entityID, err)
+		return fmt.Errorf("failed to record audit event for %s %s %s: %w", action, entityType, entityID, err)
+	}
+	return nil
+}
+
+// ListForUser returns the history of the user's own data and of the changes they made
+func (s *AuditService) ListForUser(ctx context.Context, userID uuid.UUID, query *dto.AuditEventQuery) ([]*dto.AuditEventResponse, error) {
+	scoped := *query
+	scoped.UserID = &userID
+	return s.List(ctx, &scoped)
+}
+
+// ExportForUser returns the whole history ListForUser pages through, newest first
+func (s *AuditService) ExportForUser(ctx context.Context, userID uuid.UUID) ([]*dto.AuditEventResponse, error) {
+	responses := []*dto.AuditEventResponse{}
+	for offset := 0; ; offset += maxAuditPageSize {
+		page, err := s.ListForUser(ctx, userID, &dto.AuditEventQuery{Limit: maxAuditPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, page...)
+		if len(page) < maxAuditPageSize {
+			return responses, nil
+		}
+	}
+}
+
+// List returns audit events matching the query, newest first
+func (s *AuditService) List(ctx context.Context, query *dto.AuditEventQuery) ([]*dto.AuditEventResponse, error) {
+	filter := mapper.AuditEventFilterFromQuery(query)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	events, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	responses := make([]*dto.AuditEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = mapper.AuditEventFromEntity(event)
+	}
+
+	return responses, nil
+}
+
+// PurgeExpired deletes events older than config.AuditRetention
+func (s *AuditService) PurgeExpired(ctx context.Context) error {
+	if config.AuditRetention <= 0 {
+		return nil
+	}
+
+	deleted, err := s.auditRepo.DeleteBefore(ctx, time.Now().Add(-config.AuditRetention))
+	if err != nil {
+		return fmt.Errorf("failed to purge audit events: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("audit: purged %d events older than %s", deleted, config.AuditRetention)
+	}
+
+	return nil
+}
+
+// encodeAuditDocuments stores the snapshots on the event, plus the field diff when both exist
+func encodeAuditDocuments(event *entity2.AuditEvent, before, after map[string]interface{}) error {
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	if before != nil && after != nil {
+		if event.Changes, err = json.Marshal(audit.Diff(before, after)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,45 +1,275 @@
 package service
 
 import (
+	"backend/config"
 	"backend/internal/auth"
 	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
 	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
 	"context"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	userRepo UserRepository
+	userRepo             UserRepository
+	sessionRepo          SessionRepository
+	twoFactorService     *TwoFactorService
+	loginThrottleService *LoginThrottleService
 }
 
-func NewAuthService(userRepo UserRepository) *AuthService {
+func NewAuthService(userRepo UserRepository, sessionRepo SessionRepository, twoFactorService *TwoFactorService, loginThrottleService *LoginThrottleService) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:             userRepo,
+		sessionRepo:          sessionRepo,
+		twoFactorService:     twoFactorService,
+		loginThrottleService: loginThrottleService,
 	}
 }
 
-func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	if err := s.loginThrottleService.Check(ctx, req.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
+		if err := s.loginThrottleService.RecordFailure(ctx, nil, req.Email, ipAddress); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if err := s.loginThrottleService.RecordFailure(ctx, user, req.Email, ipAddress); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	token, err := auth.GenerateToken(user.ID)
+	return s.LoginAs(ctx, user, req.DeviceName, userAgent, ipAddress)
+}
+
+// LoginAs finishes a login for a user whose identity has already been established, by password
+// or by an external provider. It applies the verification gate and the 2FA challenge.
+func (s *AuthService) LoginAs(ctx context.Context, user *entity2.User, deviceName *string, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	if config.RequireEmailVerification && user.VerifiedAt == nil {
+		return nil, fmt.Errorf("email address has not been verified")
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challengeToken, err := auth.GenerateActionToken(auth.PurposeTwoFactorLogin, user.ID, user.Email, config.TwoFactorChallengeTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+		}
+
+		return &dto.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+			User:              *mapper.UserFromEntity(user),
+		}, nil
+	}
+
+	return s.completeLogin(ctx, user, deviceName, userAgent, ipAddress)
+}
+
+// VerifyTwoFactor exchanges a login challenge token and a second factor for a full session
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, req *dto.TwoFactorVerifyRequest, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	claims, err := auth.ValidateActionToken(req.ChallengeToken, auth.PurposeTwoFactorLogin)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("invalid or expired challenge token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.TOTPEnabledAt == nil {
+		return nil, fmt.Errorf("invalid or expired challenge token")
+	}
+
+	if err := s.loginThrottleService.Check(ctx, user.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorService.VerifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		if err := s.loginThrottleService.RecordFailure(ctx, user, user.Email, ipAddress); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, req.DeviceName, userAgent, ipAddress)
+}
+
+func (s *AuthService) completeLogin(ctx context.Context, user *entity2.User, deviceName *string, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	if err := s.loginThrottleService.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	if err := s.promoteConfiguredAdmin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user, deviceName, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.LoginResponse{
-		Token: token,
-		User:  *mapper.UserFromEntity(user),
+		Token:        tokens.Token,
+		ExpiresAt:    &tokens.ExpiresAt,
+		RefreshToken: tokens.RefreshToken,
+		User:         *mapper.UserFromEntity(user),
 	}, nil
 }
+
+// promoteConfiguredAdmin makes the user an admin when config.AdminEmails lists their address. Only a
+// verified address counts, so registering a listed address does not grant the role.
+func (s *AuthService) promoteConfiguredAdmin(ctx context.Context, user *entity2.User) error {
+	if user.Role == shared.RoleAdmin || user.VerifiedAt == nil || !isConfiguredAdmin(user.Email) {
+		return nil
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, user.ID, shared.RoleAdmin); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	log.Printf("promoted %s to admin because ADMIN_EMAILS lists it", user.Email)
+	user.Role = shared.RoleAdmin
+	return nil
+}
+
+func isConfiguredAdmin(email string) bool {
+	for _, adminEmail := range config.AdminEmails {
+		if strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh exchanges a refresh token for a new access token and rotates the refresh token
+func (s *AuthService) Refresh(ctx context.Context, req *dto.RefreshTokenRequest, ipAddress string) (*dto.TokenResponse, error) {
+	previousHash := auth.HashToken(req.RefreshToken)
+
+	session, err := s.sessionRepo.GetByRefreshTokenHash(ctx, previousHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	now := time.Now()
+	if session == nil || !session.IsActive(now) {
+		return nil, fmt.Errorf("invalid or expired refresh token")
+	}
+
+	refreshToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.RefreshTokenHash = auth.HashToken(refreshToken)
+	if ipAddress != "" {
+		session.IPAddress = &ipAddress
+	}
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(config.RefreshTokenTTL)
+
+	// The role is re-read on every refresh so that role changes reach new access tokens
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("invalid or expired refresh token")
+	}
+
+	rotated, err := s.sessionRepo.Rotate(ctx, session, previousHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, fmt.Errorf("invalid or expired refresh token")
+	}
+
+	token, expiresAt, err := auth.GenerateToken(session.UserID, session.ID, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &dto.TokenResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// UnlockAccount lifts a login lockout using the link from the unlock email
+func (s *AuthService) UnlockAccount(ctx context.Context, token string) error {
+	return s.loginThrottleService.Unlock(ctx, token)
+}
+
+// Logout revokes the session the refresh token belongs to
+func (s *AuthService) Logout(ctx context.Context, req *dto.LogoutRequest) error {
+	session, err := s.sessionRepo.GetByRefreshTokenHash(ctx, auth.HashToken(req.RefreshToken))
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, session.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) startSession(ctx context.Context, user *entity2.User, deviceName *string, userAgent, ipAddress string) (*dto.TokenResponse, error) {
+	refreshToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &entity2.Session{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		RefreshTokenHash: auth.HashToken(refreshToken),
+		DeviceName:       deviceName,
+		UserAgent:        optionalString(userAgent),
+		IPAddress:        optionalString(ipAddress),
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(config.RefreshTokenTTL),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	token, expiresAt, err := auth.GenerateToken(user.ID, session.ID, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &dto.TokenResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"backend/internal/mailer"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CareGrantService struct {
+	careGrantRepo CareGrantRepository
+	userRepo      UserRepository
+	mailer        mailer.Mailer
+}
+
+func NewCareGrantService(careGrantRepo CareGrantRepository, userRepo UserRepository, mailer mailer.Mailer) *CareGrantService {
+	return &CareGrantService{
+		careGrantRepo: careGrantRepo,
+		userRepo:      userRepo,
+		mailer:        mailer,
+	}
+}
+
+// Invite creates a pending grant and emails an acceptance link to the caregiver
+func (s *CareGrantService) Invite(ctx context.Context, patientID uuid.UUID, req *dto.CareGrantInviteRequest) (*dto.CareGrantResponse, error) {
+	if req.Permission != shared.CareRead && req.Permission != shared.CareManage {
+		return nil, fmt.Errorf("invalid permission: %s", req.Permission)
+	}
+
+	patient, err := s.userRepo.GetByID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if patient == nil {
+		return nil, fmt.Errorf("user not found with id: %s", patientID)
+	}
+
+	email := normalizeEmail(req.Email)
+	if email == normalizeEmail(patient.Email) {
+		return nil, fmt.Errorf("you cannot invite yourself as a caregiver")
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant := &entity2.CareGrant{
+		ID:              uuid.New(),
+		PatientID:       patientID,
+		CaregiverEmail:  email,
+		Permission:      req.Permission,
+		InviteTokenHash: auth.HashToken(token),
+		InviteExpiresAt: now.Add(config.CareInviteTTL),
+		CreatedAt:       now,
+	}
+
+	if err := s.careGrantRepo.Create(ctx, grant); err != nil {
+		return nil, fmt.Errorf("failed to create care grant: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/care-invites/accept?token=%s", config.AppBaseURL, url.QueryEscape(token))
+	access := "view"
+	if req.Permission == shared.CareManage {
+		access = "view and record"
+	}
+	msg := mailer.Message{
+		To:      email,
+		Subject: "You have been invited as a caregiver on DoseLog",
+		Body: fmt.Sprintf("%s has invited you to %s their medications on DoseLog.\n\n"+
+			"Sign in or create an account with this email address, then open the link below to accept. "+
+			"It expires in %s.\n\n%s\n\n"+
+			"If you do not know this person, you can ignore this email.", patient.Email, access, config.CareInviteTTL, link),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return nil, fmt.Errorf("failed to send caregiver invite: %w", err)
+	}
+
+	return mapper.CareGrantFromEntity(grant, now), nil
+}
+
+// Accept binds an invite to the authenticated caregiver, whose email must match the invited address
+func (s *CareGrantService) Accept(ctx context.Context, caregiverID uuid.UUID, req *dto.CareGrantAcceptRequest) (*dto.CareGrantResponse, error) {
+	grant, err := s.careGrantRepo.GetByInviteTokenHash(ctx, auth.HashToken(req.Token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care grant: %w", err)
+	}
+	now := time.Now()
+	if grant == nil || grant.Status(now) != "pending" {
+		return nil, fmt.Errorf("invalid or expired invite")
+	}
+
+	caregiver, err := s.userRepo.GetByID(ctx, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if caregiver == nil || normalizeEmail(caregiver.Email) != grant.CaregiverEmail {
+		return nil, fmt.Errorf("this invite was sent to a different email address")
+	}
+	if caregiver.ID == grant.PatientID {
+		return nil, fmt.Errorf("you cannot accept your own invite")
+	}
+
+	existing, err := s.careGrantRepo.GetActive(ctx, grant.PatientID, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing grant: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("you already have access to this user")
+	}
+
+	accepted, err := s.careGrantRepo.Accept(ctx, grant.ID, caregiverID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept care grant: %w", err)
+	}
+	if !accepted {
+		return nil, fmt.Errorf("invalid or expired invite")
+	}
+
+	grant.CaregiverID = &caregiverID
+	grant.AcceptedAt = &now
+
+	return s.withPatientEmail(ctx, grant, now)
+}
+
+// ListGranted returns the caregivers the patient has invited or granted access to
+func (s *CareGrantService) ListGranted(ctx context.Context, patientID uuid.UUID) ([]*dto.CareGrantResponse, error) {
+	grants, err := s.careGrantRepo.ListByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care grants: %w", err)
+	}
+
+	now := time.Now()
+	responses := make([]*dto.CareGrantResponse, len(grants))
+	for i, grant := range grants {
+		responses[i] = mapper.CareGrantFromEntity(grant, now)
+	}
+
+	return responses, nil
+}
+
+// ListReceived returns the patients whose data the caregiver can currently access
+func (s *CareGrantService) ListReceived(ctx context.Context, caregiverID uuid.UUID) ([]*dto.CareGrantResponse, error) {
+	grants, err := s.careGrantRepo.ListActiveByCaregiverID(ctx, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care grants: %w", err)
+	}
+
+	now := time.Now()
+	responses := make([]*dto.CareGrantResponse, len(grants))
+	for i, grant := range grants {
+		response, err := s.withPatientEmail(ctx, grant, now)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = response
+	}
+
+	return responses, nil
+}
+
+// Revoke ends a grant; either the patient or the caregiver may do so
+func (s *CareGrantService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	grant, err := s.careGrantRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get care grant: %w", err)
+	}
+	isCaregiver := grant != nil && grant.CaregiverID != nil && *grant.CaregiverID == userID
+	if grant == nil || grant.RevokedAt != nil || (grant.PatientID != userID && !isCaregiver) {
+		return fmt.Errorf("care grant not found with id: %s", id)
+	}
+
+	if err := s.careGrantRepo.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke care grant: %w", err)
+	}
+
+	return nil
+}
+
+// CanAccess implements auth.GrantChecker: read is covered by any active grant, manage only by a manage grant
+func (s *CareGrantService) CanAccess(ctx context.Context, ownerID, actorID uuid.UUID, action auth.Action) (bool, error) {
+	grant, err := s.careGrantRepo.GetActive(ctx, ownerID, actorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get care grant: %w", err)
+	}
+	if grant == nil {
+		return false, nil
+	}
+
+	switch action {
+	case auth.ActionRead:
+		return true, nil
+	case auth.ActionManage:
+		return grant.Permission == shared.CareManage, nil
+	default:
+		return false, nil
+	}
+}
+
+func (s *CareGrantService) withPatientEmail(ctx context.Context, grant *entity2.CareGrant, now time.Time) (*dto.CareGrantResponse, error) {
+	response := mapper.CareGrantFromEntity(grant, now)
+
+	patient, err := s.userRepo.GetByID(ctx, grant.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if patient != nil {
+		response.PatientEmail = patient.Email
+	}
+
+	return response, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	"backend/internal/mailer"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailVerificationService struct {
+	userRepo UserRepository
+	mailer   mailer.Mailer
+}
+
+func NewEmailVerificationService(userRepo UserRepository, mailer mailer.Mailer) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo: userRepo,
+		mailer:   mailer,
+	}
+}
+
+// SendVerification emails a signed verification link to the user's current address
+func (s *EmailVerificationService) SendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found with id: %s", userID)
+	}
+	if user.VerifiedAt != nil {
+		return nil
+	}
+
+	token, err := auth.GenerateActionToken(auth.PurposeEmailVerification, user.ID, user.Email, config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/auth/verify-email?token=%s", config.AppBaseURL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your DoseLog email address",
+		Body: fmt.Sprintf("Welcome to DoseLog!\n\n"+
+			"Please confirm your email address by opening the link below. It expires in %s.\n\n%s", config.EmailVerificationTTL, link),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// Resend sends a new verification link; unknown or already verified addresses are ignored
+func (s *EmailVerificationService) Resend(ctx context.Context, req *dto.ResendVerificationRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	return s.SendVerification(ctx, user.ID)
+}
+
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	claims, err := auth.ValidateActionToken(token, auth.PurposeEmailVerification)
+	if err != nil {
+		return fmt.Errorf("invalid or expired verification token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	// The link is only valid for the address it was sent to
+	if user == nil || user.Email != claims.Email {
+		return fmt.Errorf("invalid or expired verification token")
+	}
+	if user.VerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.userRepo.MarkVerified(ctx, user.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxIngredientNameLength = 200
+	// maxInteractionImportRows is higher than MaxImportRows: reference interaction tables are large
+	maxInteractionImportRows = 200000
+)
+
+var interactionImportColumns = []string{"ingredient_a", "ingredient_b", "severity", "description"}
+
+// InteractionBlockedError is returned when a medication interacts severely with one the profile already takes
+type InteractionBlockedError struct {
+	Interactions []dto.InteractionWarning
+}
+
+func (e *InteractionBlockedError) Error() string {
+	for _, interaction := range e.Interactions {
+		if interaction.Severity.Blocks() {
+			return fmt.Sprintf("severe interaction between %s and %s (in %s): %s",
+				interaction.Ingredient, interaction.OtherIngredient, interaction.MedicationName, interaction.Description)
+		}
+	}
+	return "severe interaction with an active medication"
+}
+
+type InteractionService struct {
+	ingredientRepo IngredientRepository
+	medicationRepo MedicationRepository
+}
+
+func NewInteractionService(ingredientRepo IngredientRepository, medicationRepo MedicationRepository) *InteractionService {
+	return &InteractionService{
+		ingredientRepo: ingredientRepo,
+		medicationRepo: medicationRepo,
+	}
+}
+
+func (s *InteractionService) ListIngredients(ctx context.Context, medicationID uuid.UUID) ([]*dto.MedicationIngredientResponse, error) {
+	ingredients, err := s.ingredientRepo.ListByMedicationID(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingredients: %w", err)
+	}
+
+	responses := make([]*dto.MedicationIngredientResponse, len(ingredients))
+	for i, ingredient := range ingredients {
+		responses[i] = mapper.MedicationIngredientFromEntity(ingredient)
+	}
+
+	return responses, nil
+}
+
+// SetIngredients replaces the active ingredients of a catalog entry; unknown ingredients are created
+func (s *InteractionService) SetIngredients(ctx context.Context, medicationID uuid.UUID, req *dto.MedicationIngredientsRequest) ([]*dto.MedicationIngredientResponse, error) {
+	medication, err := s.medicationRepo.GetByID(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication: %w", err)
+	}
+	if medication == nil {
+		return nil, fmt.Errorf("medication not found with id: %s", medicationID)
+	}
+
+	seen := make(map[string]bool)
+	ingredients := make([]*entity2.MedicationIngredient, 0, len(req.Ingredients))
+	for _, item := range req.Ingredients {
+		name := strings.TrimSpace(item.Name)
+		if name == "" || len(name) > maxIngredientNameLength {
+			return nil, fmt.Errorf("ingredient name must be between 1 and %d characters", maxIngredientNameLength)
+		}
+		if item.AmountMg != nil && *item.AmountMg <= 0 {
+			return nil, fmt.Errorf("amount_mg of %s must be greater than 0", name)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("ingredient listed twice: %s", name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		ingredients = append(ingredients, &entity2.MedicationIngredient{
+			MedicationID:   medicationID,
+			IngredientName: name,
+			AmountMg:       item.AmountMg,
+		})
+	}
+
+	if err := s.ingredientRepo.ReplaceForMedication(ctx, medicationID, ingredients); err != nil {
+		return nil, fmt.Errorf("failed to set ingredients: %w", err)
+	}
+
+	return s.ListIngredients(ctx, medicationID)
+}
+
+// Check lists the interactions between a medication and the other medications, most severe first
+func (s *InteractionService) Check(ctx context.Context, medicationID uuid.UUID, otherMedicationIDs []uuid.UUID) ([]dto.InteractionWarning, error) {
+	matches, err := s.ingredientRepo.FindInteractions(ctx, medicationID, otherMedicationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check interactions: %w", err)
+	}
+
+	warnings := make([]dto.InteractionWarning, len(matches))
+	for i, match := range matches {
+		warnings[i] = mapper.InteractionWarningFromEntity(match)
+	}
+
+	return warnings, nil
+}
+
+// CheckActive compares a medication with the active trackings of a profile. Severe interactions
+// are returned as an InteractionBlockedError; milder ones are returned as warnings.
+func (s *InteractionService) CheckActive(ctx context.Context, medicationID uuid.UUID, active []*entity2.UserMedication) ([]dto.InteractionWarning, error) {
+	otherIDs := make([]uuid.UUID, 0, len(active))
+	for _, um := range active {
+		if um.MedicationID != medicationID {
+			otherIDs = append(otherIDs, um.MedicationID)
+		}
+	}
+	if len(otherIDs) == 0 {
+		return nil, nil
+	}
+
+	warnings, err := s.Check(ctx, medicationID, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		if warning.Severity.Blocks() {
+			return nil, &InteractionBlockedError{Interactions: warnings}
+		}
+	}
+
+	return warnings, nil
+}
+
+// ImportInteractions loads a CSV reference table with the columns ingredient_a, ingredient_b,
+// severity and description. Existing pairs are replaced and unknown ingredients are created.
+func (s *InteractionService) ImportInteractions(ctx context.Context, r io.Reader, dryRun bool) (*dto.InteractionImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range interactionImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing required CSV column %q", name)
+		}
+	}
+
+	report := &dto.InteractionImportReport{
+		DryRun: dryRun,
+		Errors: []*dto.InteractionImportRowError{},
+	}
+	now := time.Now()
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if report.Total >= maxInteractionImportRows {
+			return nil, fmt.Errorf("too many rows, at most %d are allowed per import", maxInteractionImportRows)
+		}
+		report.Total++
+
+		rowError := &dto.InteractionImportRowError{Row: line}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowError.Errors = []string{parseErr.Err.Error()}
+			report.Failed++
+			report.Errors = append(report.Errors, rowError)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		interaction := &entity2.Interaction{
+			IngredientA: field("ingredient_a"),
+			IngredientB: field("ingredient_b"),
+			Severity:    shared.InteractionSeverity(strings.ToLower(field("severity"))),
+			Description: field("description"),
+			UpdatedAt:   now,
+		}
+		rowError.IngredientA = interaction.IngredientA
+		rowError.IngredientB = interaction.IngredientB
+
+		rowError.Errors = validateInteraction(interaction)
+		if len(rowError.Errors) == 0 && !dryRun {
+			if err := s.ingredientRepo.UpsertInteraction(ctx, interaction); err != nil {
+				rowError.Errors = []string{fmt.Sprintf("failed to store interaction: %v", err)}
+			}
+		}
+
+		if len(rowError.Errors) > 0 {
+			report.Failed++
+			report.Errors = append(report.Errors, rowError)
+			continue
+		}
+		report.Imported++
+	}
+
+	return report, nil
+}
+
+func validateInteraction(interaction *entity2.Interaction) []string {
+	var problems []string
+	for _, name := range []string{interaction.IngredientA, interaction.IngredientB} {
+		if name == "" || len(name) > maxIngredientNameLength {
+			problems = append(problems, fmt.Sprintf("ingredient names must be between 1 and %d characters", maxIngredientNameLength))
+			break
+		}
+	}
+	if interaction.IngredientA != "" && strings.EqualFold(interaction.IngredientA, interaction.IngredientB) {
+		problems = append(problems, "an ingredient cannot interact with itself")
+	}
+	switch interaction.Severity {
+	case shared.InteractionMinor, shared.InteractionModerate, shared.InteractionSevere:
+	default:
+		problems = append(problems, fmt.Sprintf("severity must be one of minor, moderate, severe, got %q", interaction.Severity))
+	}
+	if interaction.Description == "" {
+		problems = append(problems, "description is required")
+	}
+	return problems
+}
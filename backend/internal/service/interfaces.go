@@ -2,6 +2,7 @@ package service
 
 import (
 	entity2 "backend/internal/core/entity"
+	"backend/internal/core/shared"
 	"context"
 	"time"
 
@@ -13,15 +14,31 @@ type UserRepository interface {
 	Create(ctx context.Context, user *entity2.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity2.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity2.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+	MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error
+	SetTOTPSecret(ctx context.Context, id uuid.UUID, secret *string) error
+	EnableTOTP(ctx context.Context, id uuid.UUID, enabledAt time.Time) error
+	UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role shared.Role) error
 }
 
 // MedicationRepository defines the medication data access methods needed by MedicationService
 type MedicationRepository interface {
 	Create(ctx context.Context, med *entity2.Medication) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity2.Medication, error)
-	GetByName(ctx context.Context, name string) (*entity2.Medication, error)
+	GetByName(ctx context.Context, name string, ownerID *uuid.UUID) (*entity2.Medication, error)
+	GetByExternalCode(ctx context.Context, code string, ownerID *uuid.UUID) (*entity2.Medication, error)
+	ListByBarcodes(ctx context.Context, codes []string) ([]*entity2.Medication, error)
 	Update(ctx context.Context, med *entity2.Medication) error
-	List(ctx context.Context, limit, offset int) ([]*entity2.Medication, error)
+	Search(ctx context.Context, filter entity2.MedicationSearchFilter) ([]*entity2.MedicationSearchHit, int, error)
+	ListByStatus(ctx context.Context, status shared.MedicationStatus, limit, offset int) ([]*entity2.Medication, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status shared.MedicationStatus, reviewedBy uuid.UUID, reviewedAt time.Time) error
+	SetArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time, archivedBy *uuid.UUID) error
+	CountReferences(ctx context.Context, id uuid.UUID) (int, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) ([]*entity2.UserMedication, error)
+	FindDuplicates(ctx context.Context, query entity2.MedicationDuplicateQuery) ([]*entity2.MedicationDuplicate, error)
 }
 
 // UserMedicationRepository defines the user medication data access methods needed by UserMedicationService
@@ -29,7 +46,9 @@ type UserMedicationRepository interface {
 	Create(ctx context.Context, um *entity2.UserMedication) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity2.UserMedication, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.UserMedication, error)
-	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.UserMedication, error)
+	GetByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity2.UserMedication, error)
+	GetActiveByProfileID(ctx context.Context, profileID uuid.UUID) ([]*entity2.UserMedication, error)
+	GetActiveByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity2.UserMedication, error)
 	Update(ctx context.Context, um *entity2.UserMedication) error
 }
 
@@ -41,3 +60,119 @@ type MedicationLogRepository interface {
 	GetByUserMedicationIDAndDateRange(ctx context.Context, userMedicationID uuid.UUID, start, end time.Time) ([]*entity2.MedicationLog, error)
 	Update(ctx context.Context, log *entity2.MedicationLog) error
 }
+
+// SessionRepository defines the session data access methods needed by AuthService and SessionService
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity2.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity2.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*entity2.Session, error)
+	Rotate(ctx context.Context, session *entity2.Session, previousHash string) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity2.Session, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.Session, error)
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, exceptID *uuid.UUID, revokedAt time.Time) error
+}
+
+// PasswordResetRepository defines the reset token data access methods needed by PasswordResetService
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *entity2.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, hash string) (*entity2.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error)
+	InvalidateByUserID(ctx context.Context, userID uuid.UUID, usedAt time.Time) error
+}
+
+// AccountDeletionRepository defines the deletion workflow data access methods needed by AccountService
+type AccountDeletionRepository interface {
+	Create(ctx context.Context, req *entity2.AccountDeletionRequest) error
+	GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*entity2.AccountDeletionRequest, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.AccountDeletionRequest, error)
+	Cancel(ctx context.Context, id uuid.UUID, cancelledAt time.Time) error
+	CompleteDue(ctx context.Context, now time.Time, limit int) ([]*entity2.AccountDeletionRequest, error)
+}
+
+// RecoveryCodeRepository defines the 2FA recovery code data access methods needed by TwoFactorService
+type RecoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []*entity2.RecoveryCode) error
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// LoginThrottleRepository defines the failed login tracking methods needed by LoginThrottleService
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, key string) (*entity2.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, windowStart time.Time) (*entity2.LoginThrottle, error)
+	Lock(ctx context.Context, key string, lockedUntil time.Time) error
+	Reset(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, before time.Time) error
+}
+
+// CareGrantRepository defines the caregiver grant data access methods needed by CareGrantService
+type CareGrantRepository interface {
+	Create(ctx context.Context, grant *entity2.CareGrant) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity2.CareGrant, error)
+	GetByInviteTokenHash(ctx context.Context, hash string) (*entity2.CareGrant, error)
+	GetActive(ctx context.Context, patientID, caregiverID uuid.UUID) (*entity2.CareGrant, error)
+	ListByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entity2.CareGrant, error)
+	ListActiveByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entity2.CareGrant, error)
+	Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+}
+
+// ProfileRepository defines the profile data access methods needed by ProfileService
+type ProfileRepository interface {
+	Create(ctx context.Context, profile *entity2.Profile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity2.Profile, error)
+	GetDefaultByUserID(ctx context.Context, userID uuid.UUID) (*entity2.Profile, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.Profile, error)
+	Update(ctx context.Context, profile *entity2.Profile) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PersonalAccessTokenRepository defines the personal access token data access methods needed by PersonalAccessTokenService
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *entity2.PersonalAccessToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity2.PersonalAccessToken, error)
+	GetByTokenHash(ctx context.Context, hash string) (*entity2.PersonalAccessToken, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.PersonalAccessToken, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error
+}
+
+// OIDCStateRepository defines the pending OIDC login data access methods needed by OIDCService
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *entity2.OIDCLoginState) error
+	Consume(ctx context.Context, stateHash string) (*entity2.OIDCLoginState, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// UserIdentityRepository defines the linked identity data access methods needed by OIDCService
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity2.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entity2.UserIdentity, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.UserIdentity, error)
+	TouchLastLogin(ctx context.Context, id uuid.UUID, email *string, at time.Time) error
+}
+
+// AuditEventRepository defines the audit trail data access methods needed by AuditService
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *entity2.AuditEvent) error
+	List(ctx context.Context, filter entity2.AuditEventFilter) ([]*entity2.AuditEvent, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// IngredientRepository defines the ingredient and interaction data access methods needed by InteractionService
+type IngredientRepository interface {
+	ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity2.MedicationIngredient, error)
+	ReplaceForMedication(ctx context.Context, medicationID uuid.UUID, ingredients []*entity2.MedicationIngredient) error
+	UpsertInteraction(ctx context.Context, interaction *entity2.Interaction) error
+	FindInteractions(ctx context.Context, medicationID uuid.UUID, otherMedicationIDs []uuid.UUID) ([]*entity2.InteractionMatch, error)
+}
+
+// AttachmentRepository defines the medication attachment data access methods needed by AttachmentService
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *entity2.MedicationAttachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity2.MedicationAttachment, error)
+	ListByMedicationID(ctx context.Context, medicationID uuid.UUID) ([]*entity2.MedicationAttachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/mailer"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TooManyAttemptsError is returned while an account or client IP is locked out
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+type LoginThrottleService struct {
+	loginThrottleRepo LoginThrottleRepository
+	mailer            mailer.Mailer
+}
+
+func NewLoginThrottleService(loginThrottleRepo LoginThrottleRepository, mailer mailer.Mailer) *LoginThrottleService {
+	return &LoginThrottleService{
+		loginThrottleRepo: loginThrottleRepo,
+		mailer:            mailer,
+	}
+}
+
+// Check returns a TooManyAttemptsError if either the account or the client IP is locked
+func (s *LoginThrottleService) Check(ctx context.Context, email, ipAddress string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, key := range []string{accountKey(email), ipKey(ipAddress)} {
+		throttle, err := s.loginThrottleRepo.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to get login throttle: %w", err)
+		}
+		if throttle != nil && throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			if wait := throttle.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt for the account and the IP and locks them once
+// their threshold is reached. user is nil when the email does not belong to an account.
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, user *entity2.User, email, ipAddress string) error {
+	accountThrottle, err := s.recordFailure(ctx, accountKey(email), config.LoginMaxAccountFailures)
+	if err != nil {
+		return err
+	}
+	if _, err := s.recordFailure(ctx, ipKey(ipAddress), config.LoginMaxIPFailures); err != nil {
+		return err
+	}
+
+	// Only the first lock inside a window triggers an email, so attackers cannot flood the inbox
+	if user != nil && accountThrottle.Failures == config.LoginMaxAccountFailures {
+		s.sendUnlockEmail(ctx, user)
+	}
+
+	return nil
+}
+
+// RecordSuccess clears the account counter after a successful login
+func (s *LoginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	if err := s.loginThrottleRepo.Reset(ctx, accountKey(email)); err != nil {
+		return fmt.Errorf("failed to reset login throttle: %w", err)
+	}
+	return nil
+}
+
+// Unlock lifts an account lockout using the token from the unlock email
+func (s *LoginThrottleService) Unlock(ctx context.Context, token string) error {
+	claims, err := auth.ValidateActionToken(token, auth.PurposeAccountUnlock)
+	if err != nil {
+		return fmt.Errorf("invalid or expired unlock token")
+	}
+
+	if err := s.loginThrottleRepo.Reset(ctx, accountKey(claims.Email)); err != nil {
+		return fmt.Errorf("failed to reset login throttle: %w", err)
+	}
+	return nil
+}
+
+// PurgeStale removes counters that are outside the failure window and no longer locked
+func (s *LoginThrottleService) PurgeStale(ctx context.Context) error {
+	if err := s.loginThrottleRepo.DeleteStale(ctx, time.Now().Add(-config.LoginFailureWindow)); err != nil {
+		return fmt.Errorf("failed to purge login throttles: %w", err)
+	}
+	return nil
+}
+
+func (s *LoginThrottleService) recordFailure(ctx context.Context, key string, threshold int) (*entity2.LoginThrottle, error) {
+	now := time.Now()
+	throttle, err := s.loginThrottleRepo.RecordFailure(ctx, key, now, now.Add(-config.LoginFailureWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	if throttle.Failures >= threshold {
+		if err := s.loginThrottleRepo.Lock(ctx, key, now.Add(lockoutDuration(throttle.Failures-threshold))); err != nil {
+			return nil, fmt.Errorf("failed to lock login: %w", err)
+		}
+	}
+
+	return throttle, nil
+}
+
+func (s *LoginThrottleService) sendUnlockEmail(ctx context.Context, user *entity2.User) {
+	token, err := auth.GenerateActionToken(auth.PurposeAccountUnlock, user.ID, normalizeEmail(user.Email), config.AccountUnlockTTL)
+	if err != nil {
+		log.Printf("failed to generate unlock token for user %s: %v", user.ID, err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/api/auth/unlock?token=%s", config.AppBaseURL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your DoseLog account was temporarily locked",
+		Body: "We locked your DoseLog account after several failed login attempts.\n\n" +
+			"If this was you, open the link below to unlock it now:\n\n" + link + "\n\n" +
+			"If it was not you, consider changing your password.",
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("failed to send unlock email to user %s: %v", user.ID, err)
+	}
+}
+
+// lockoutDuration doubles the base lockout for every failure past the threshold
+func lockoutDuration(excessFailures int) time.Duration {
+	d := config.LoginLockoutBase
+	for i := 0; i < excessFailures && d < config.LoginLockoutMax; i++ {
+		d *= 2
+	}
+	if d > config.LoginLockoutMax {
+		d = config.LoginLockoutMax
+	}
+	return d
+}
+
+func accountKey(email string) string {
+	return "account:" + normalizeEmail(email)
+}
+
+func ipKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
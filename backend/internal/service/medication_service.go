@@ -2,55 +2,202 @@ package service
 
 import (
 	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
 	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// DuplicateMedicationError is returned by Create when the catalog already holds probable duplicates
+// of the new entry; AllowDuplicates on the request overrides it
+type DuplicateMedicationError struct {
+	Duplicates []*dto.MedicationDuplicateResponse
+}
+
+func (e *DuplicateMedicationError) Error() string {
+	return fmt.Sprintf("%d similar medications already exist; use one of them or set allow_duplicates", len(e.Duplicates))
+}
+
+// MedicationInUseError is returned when deleting a medication that user medications still track
+type MedicationInUseError struct {
+	References int
+}
+
+func (e *MedicationInUseError) Error() string {
+	return fmt.Sprintf("medication is tracked by %d user medications; archive it or merge it into another entry instead", e.References)
+}
+
+// MedicationValidationError lists every rule a created or updated medication breaks
+type MedicationValidationError struct {
+	Problems []string
+}
+
+func (e *MedicationValidationError) Error() string {
+	return fmt.Sprintf("invalid medication: %s", strings.Join(e.Problems, "; "))
+}
+
+// MedicationConflictError is returned when an external code or a barcode already belongs to
+// another entry of the same catalog
+type MedicationConflictError struct {
+	Conflicts []string
+}
+
+func (e *MedicationConflictError) Error() string {
+	return strings.Join(e.Conflicts, "; ")
+}
+
 type MedicationService struct {
-	medicationRepo MedicationRepository
+	medicationRepo     MedicationRepository
+	userMedicationRepo UserMedicationRepository
+	interactionService *InteractionService
+	auditService       *AuditService
 }
 
-func NewMedicationService(medicationRepo MedicationRepository) *MedicationService {
+func NewMedicationService(medicationRepo MedicationRepository, userMedicationRepo UserMedicationRepository, interactionService *InteractionService, auditService *AuditService) *MedicationService {
 	return &MedicationService{
-		medicationRepo: medicationRepo,
+		medicationRepo:     medicationRepo,
+		userMedicationRepo: userMedicationRepo,
+		interactionService: interactionService,
+		auditService:       auditService,
 	}
 }
 
-func (s *MedicationService) Create(ctx context.Context, req *dto.MedicationCreateRequest) (*dto.MedicationResponse, error) {
-	existingMed, err := s.medicationRepo.GetByName(ctx, req.Name)
+// Create adds a catalog entry. Shared submissions from roles that cannot manage the catalog
+// are stored as pending until a pharmacist or admin reviews them; private entries are not reviewed.
+func (s *MedicationService) Create(ctx context.Context, userID uuid.UUID, role shared.Role, req *dto.MedicationCreateRequest) (*dto.MedicationResponse, error) {
+	normalizeMedicationCreate(req)
+	if problems := validateMedicationCreate(req); len(problems) > 0 {
+		return nil, &MedicationValidationError{Problems: problems}
+	}
+
+	var ownerID *uuid.UUID
+	if req.Visibility == shared.VisibilityPrivate {
+		ownerID = &userID
+	}
+
+	existingMed, err := s.medicationRepo.GetByName(ctx, req.Name, ownerID)
 	if err != nil {
 		return nil, err
 	}
 	if existingMed != nil {
 		return nil, fmt.Errorf("medication already exists with name: %s", req.Name)
 	}
+	if req.ExternalCode != nil {
+		existingMed, err = s.medicationRepo.GetByExternalCode(ctx, *req.ExternalCode, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if existingMed != nil {
+			return nil, &MedicationConflictError{Conflicts: []string{fmt.Sprintf("medication already exists with external code: %s", *req.ExternalCode)}}
+		}
+	}
+	conflicts, err := barcodeConflicts(ctx, s.medicationRepo, req.Barcodes, ownerID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, &MedicationConflictError{Conflicts: conflicts}
+	}
+
+	if !req.AllowDuplicates {
+		duplicates, err := s.findDuplicates(ctx, entity2.MedicationDuplicateQuery{
+			Name:         req.Name,
+			Form:         req.Form,
+			Strength:     req.Strength,
+			StrengthUnit: req.StrengthUnit,
+			OwnerID:      ownerID,
+		}, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if len(duplicates) > 0 {
+			return nil, &DuplicateMedicationError{Duplicates: duplicates}
+		}
+	}
 
 	medication := mapper.MedicationToEntity(req)
+	switch {
+	case medication.Visibility == shared.VisibilityPrivate:
+		// Nobody else sees a private entry, so there is nothing to review
+		medication.OwnerID = ownerID
+		medication.Status = shared.MedicationApproved
+		medication.SubmittedBy = &userID
+	case role.CanManageCatalog():
+		medication.Status = shared.MedicationApproved
+	default:
+		medication.Status = shared.MedicationPending
+		medication.SubmittedBy = &userID
+	}
 
 	if err := s.medicationRepo.Create(ctx, medication); err != nil {
 		return nil, fmt.Errorf("failed to create medication: %w", err)
 	}
+	if err := s.auditService.Record(ctx, shared.AuditCreate, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, nil, medication); err != nil {
+		return nil, err
+	}
 
 	return mapper.MedicationFromEntity(medication), nil
 }
 
+// Update changes a catalog entry and returns nil when it does not exist. Broken rules are
+// reported as a MedicationValidationError, codes another entry carries as a MedicationConflictError.
 func (s *MedicationService) Update(ctx context.Context, id uuid.UUID, req *dto.MedicationUpdateRequest) (*dto.MedicationResponse, error) {
 	medication, err := s.medicationRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get medication: %w", err)
 	}
 	if medication == nil {
-		return nil, fmt.Errorf("medication not found with id: %s", id)
+		return nil, nil
+	}
+
+	if req.Barcodes != nil {
+		barcodes := normalizeBarcodes(*req.Barcodes)
+		req.Barcodes = &barcodes
 	}
 
+	before := *medication
 	mapper.UpdateMedicationEntity(medication, req)
+	problems := validateMedicationUnits(medication.Strength, medication.StrengthUnit, medication.PackageSize, medication.PackageUnit)
+	if req.Barcodes != nil {
+		problems = append(problems, validateBarcodes(*req.Barcodes)...)
+	}
+	if len(problems) > 0 {
+		return nil, &MedicationValidationError{Problems: problems}
+	}
+	if req.Barcodes != nil {
+		conflicts, err := barcodeConflicts(ctx, s.medicationRepo, *req.Barcodes, medication.OwnerID, medication.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			return nil, &MedicationConflictError{Conflicts: conflicts}
+		}
+	}
+	if req.ExternalCode != nil {
+		existingMed, err := s.medicationRepo.GetByExternalCode(ctx, *req.ExternalCode, medication.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		if existingMed != nil && existingMed.ID != medication.ID {
+			return nil, &MedicationConflictError{Conflicts: []string{fmt.Sprintf("medication already exists with external code: %s", *req.ExternalCode)}}
+		}
+	}
 
 	if err := s.medicationRepo.Update(ctx, medication); err != nil {
 		return nil, fmt.Errorf("failed to update medication: %w", err)
 	}
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, &before, medication); err != nil {
+		return nil, err
+	}
 
 	return mapper.MedicationFromEntity(medication), nil
 }
@@ -67,10 +214,60 @@ func (s *MedicationService) GetByID(ctx context.Context, id uuid.UUID) (*dto.Med
 	return mapper.MedicationFromEntity(medication), nil
 }
 
-func (s *MedicationService) List(ctx context.Context, limit, offset int) ([]*dto.MedicationResponse, error) {
-	medications, err := s.medicationRepo.List(ctx, limit, offset)
+// GetVisible returns the medication if the user may see it: approved entries are public,
+// pending or rejected ones only to their submitter and to catalog managers
+func (s *MedicationService) GetVisible(ctx context.Context, id, userID uuid.UUID, role shared.Role) (*dto.MedicationResponse, error) {
+	medication, err := s.medicationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication by id: %w", err)
+	}
+	if medication == nil || !isVisible(medication, userID, role) {
+		return nil, nil
+	}
+
+	return mapper.MedicationFromEntity(medication), nil
+}
+
+// GetByBarcode finds the entry a scanned box belongs to among the ones the user sees, preferring the
+// user's own private entry, then an approved shared one, and prefills a tracking request from it.
+// The code must be a normalized GTIN. It returns nil when no entry matches.
+func (s *MedicationService) GetByBarcode(ctx context.Context, code string, userID uuid.UUID, role shared.Role) (*dto.MedicationBarcodeResponse, error) {
+	medications, err := s.medicationRepo.ListByBarcodes(ctx, []string{code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medications by barcode: %w", err)
+	}
+
+	var match *entity2.Medication
+	for _, medication := range medications {
+		if medication.ArchivedAt != nil || !isVisible(medication, userID, role) {
+			continue
+		}
+		if medication.Visibility == shared.VisibilityPrivate {
+			match = medication
+			break
+		}
+		if match == nil || match.Status != shared.MedicationApproved && medication.Status == shared.MedicationApproved {
+			match = medication
+		}
+	}
+	if match == nil {
+		return nil, nil
+	}
+
+	return &dto.MedicationBarcodeResponse{
+		Medication: mapper.MedicationFromEntity(match),
+		Prefill: dto.UserMedicationCreateRequest{
+			MedicationID: match.ID,
+			BoxesOwned:   1,
+			Schedules:    []dto.IntakeSchedule{},
+		},
+	}, nil
+}
+
+func (s *MedicationService) ListPending(ctx context.Context, limit, offset int) ([]*dto.MedicationResponse, error) {
+	medications, err := s.medicationRepo.ListByStatus(ctx, shared.MedicationPending, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list medications: %w", err)
+		return nil, fmt.Errorf("failed to list pending medications: %w", err)
 	}
 
 	responses := make([]*dto.MedicationResponse, len(medications))
@@ -80,3 +277,590 @@ func (s *MedicationService) List(ctx context.Context, limit, offset int) ([]*dto
 
 	return responses, nil
 }
+
+// Review approves or rejects a pending submission
+func (s *MedicationService) Review(ctx context.Context, id, reviewerID uuid.UUID, status shared.MedicationStatus) (*dto.MedicationResponse, error) {
+	medication, err := s.medicationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication: %w", err)
+	}
+	if medication == nil {
+		return nil, fmt.Errorf("medication not found with id: %s", id)
+	}
+	if medication.Status != shared.MedicationPending {
+		return nil, fmt.Errorf("medication is not pending review")
+	}
+
+	now := time.Now()
+	if err := s.medicationRepo.UpdateStatus(ctx, id, status, reviewerID, now); err != nil {
+		return nil, fmt.Errorf("failed to review medication: %w", err)
+	}
+
+	before := *medication
+	medication.Status = status
+	medication.ReviewedBy = &reviewerID
+	medication.ReviewedAt = &now
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, &before, medication); err != nil {
+		return nil, err
+	}
+
+	return mapper.MedicationFromEntity(medication), nil
+}
+
+// FindDuplicatesOf lists probable duplicates of a shared entry, the candidates to merge it with
+func (s *MedicationService) FindDuplicatesOf(ctx context.Context, id, userID uuid.UUID, role shared.Role) ([]*dto.MedicationDuplicateResponse, error) {
+	medication, err := s.getExisting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findDuplicates(ctx, entity2.MedicationDuplicateQuery{
+		Name:         medication.Name,
+		Form:         medication.Form,
+		Strength:     medication.Strength,
+		StrengthUnit: medication.StrengthUnit,
+		OwnerID:      medication.OwnerID,
+		ExcludeID:    &medication.ID,
+	}, userID, role)
+}
+
+// findDuplicates returns the probable duplicates the user is allowed to see
+func (s *MedicationService) findDuplicates(ctx context.Context, query entity2.MedicationDuplicateQuery, userID uuid.UUID, role shared.Role) ([]*dto.MedicationDuplicateResponse, error) {
+	query.Limit = maxDuplicateCandidates
+	duplicates, err := s.medicationRepo.FindDuplicates(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for duplicate medications: %w", err)
+	}
+
+	responses := make([]*dto.MedicationDuplicateResponse, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		if isVisible(&duplicate.Medication, userID, role) {
+			responses = append(responses, mapper.MedicationDuplicateFromEntity(duplicate))
+		}
+	}
+	return responses, nil
+}
+
+// Archive hides the medication from search and from new trackings; existing trackings keep resolving it
+func (s *MedicationService) Archive(ctx context.Context, id, userID uuid.UUID) (*dto.MedicationResponse, error) {
+	medication, err := s.getExisting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if medication.ArchivedAt != nil {
+		return nil, fmt.Errorf("medication is already archived")
+	}
+
+	now := time.Now()
+	if err := s.medicationRepo.SetArchived(ctx, id, &now, &userID); err != nil {
+		return nil, fmt.Errorf("failed to archive medication: %w", err)
+	}
+
+	before := *medication
+	medication.ArchivedAt = &now
+	medication.ArchivedBy = &userID
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, &before, medication); err != nil {
+		return nil, err
+	}
+
+	return mapper.MedicationFromEntity(medication), nil
+}
+
+func (s *MedicationService) Restore(ctx context.Context, id uuid.UUID) (*dto.MedicationResponse, error) {
+	medication, err := s.getExisting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if medication.ArchivedAt == nil {
+		return nil, fmt.Errorf("medication is not archived")
+	}
+
+	if err := s.medicationRepo.SetArchived(ctx, id, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to restore medication: %w", err)
+	}
+
+	before := *medication
+	medication.ArchivedAt = nil
+	medication.ArchivedBy = nil
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, &before, medication); err != nil {
+		return nil, err
+	}
+
+	return mapper.MedicationFromEntity(medication), nil
+}
+
+// Delete removes a catalog entry for good. It is refused with MedicationInUseError while any
+// user medication tracks the entry; archive it or merge it into another entry instead.
+func (s *MedicationService) Delete(ctx context.Context, id uuid.UUID) error {
+	medication, err := s.getExisting(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.medicationRepo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete medication: %w", err)
+	}
+	if !deleted {
+		references, err := s.medicationRepo.CountReferences(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to count medication references: %w", err)
+		}
+		if references == 0 {
+			return fmt.Errorf("medication not found with id: %s", id)
+		}
+		return &MedicationInUseError{References: references}
+	}
+	return s.auditService.Record(ctx, shared.AuditDelete, shared.AuditEntityMedication, medication.ID, medication.SubmittedBy, medication, nil)
+}
+
+// Merge folds a duplicate shared entry into another one: its trackings, attachments, barcodes and
+// missing ingredients move to the target and the duplicate is deleted. The entries must count
+// doses the same way, and every moved tracking is checked for interactions as if it started on
+// the target.
+func (s *MedicationService) Merge(ctx context.Context, sourceID, targetID uuid.UUID) (*dto.MedicationMergeResponse, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("a medication cannot be merged into itself")
+	}
+	source, err := s.getExisting(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getExisting(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Visibility != shared.VisibilityShared || target.Visibility != shared.VisibilityShared {
+		return nil, fmt.Errorf("only shared catalog entries can be merged")
+	}
+	if target.ArchivedAt != nil {
+		return nil, fmt.Errorf("cannot merge into an archived medication")
+	}
+	if err := checkMergeCompatible(source, target); err != nil {
+		return nil, err
+	}
+	warnings, err := s.checkMergeInteractions(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := s.medicationRepo.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge medications: %w", err)
+	}
+
+	for _, um := range moved {
+		before := *um
+		before.MedicationID = sourceID
+		if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityUserMedication, um.ID, &um.UserID, &before, um); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.auditService.Record(ctx, shared.AuditDelete, shared.AuditEntityMedication, source.ID, source.SubmittedBy, source, nil); err != nil {
+		return nil, err
+	}
+
+	return &dto.MedicationMergeResponse{
+		Medication:           mapper.MedicationFromEntity(target),
+		MovedUserMedications: len(moved),
+		InteractionWarnings:  warnings,
+	}, nil
+}
+
+// checkMergeCompatible refuses merges that would change what the moved trackings mean: their
+// schedules, logs and stock are counted in the source's package and strength units
+func checkMergeCompatible(source, target *entity2.Medication) error {
+	if source.Form != target.Form {
+		return fmt.Errorf("cannot merge a %s into a %s", source.Form, target.Form)
+	}
+	if source.PackageUnit != target.PackageUnit {
+		return fmt.Errorf("cannot merge a medication counted in %s into one counted in %s", source.PackageUnit, target.PackageUnit)
+	}
+	if !sameStrength(source, target) {
+		return fmt.Errorf("cannot merge a strength of %g %s into a strength of %g %s",
+			source.Strength, source.StrengthUnit, target.Strength, target.StrengthUnit)
+	}
+	return nil
+}
+
+// sameStrength compares strengths as FindDuplicates does: masses in mg, other units as given
+func sameStrength(a, b *entity2.Medication) bool {
+	if a.StrengthUnit == b.StrengthUnit && a.Strength == b.Strength {
+		return true
+	}
+	aMg, aMass := a.StrengthUnit.Milligrams(float64(a.Strength))
+	bMg, bMass := b.StrengthUnit.Milligrams(float64(b.Strength))
+	return aMass && bMass && math.Abs(aMg-bMg) <= 0.0001*bMg
+}
+
+// checkMergeInteractions checks the active trackings of source against the other medications of
+// their profiles as if they tracked target, which is what they will do after the merge
+func (s *MedicationService) checkMergeInteractions(ctx context.Context, sourceID, targetID uuid.UUID) ([]dto.InteractionWarning, error) {
+	trackings, err := s.userMedicationRepo.GetActiveByMedicationID(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user medications: %w", err)
+	}
+
+	var warnings []dto.InteractionWarning
+	checked := make(map[uuid.UUID]bool)
+	for _, tracking := range trackings {
+		if checked[tracking.ProfileID] {
+			continue
+		}
+		checked[tracking.ProfileID] = true
+
+		active, err := s.userMedicationRepo.GetActiveByProfileID(ctx, tracking.ProfileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active user medications: %w", err)
+		}
+		others := slices.DeleteFunc(active, func(um *entity2.UserMedication) bool { return um.MedicationID == sourceID })
+
+		profileWarnings, err := s.interactionService.CheckActive(ctx, targetID, others)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, profileWarnings...)
+	}
+	return warnings, nil
+}
+
+func (s *MedicationService) getExisting(ctx context.Context, id uuid.UUID) (*entity2.Medication, error) {
+	medication, err := s.medicationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medication: %w", err)
+	}
+	if medication == nil {
+		return nil, fmt.Errorf("medication not found with id: %s", id)
+	}
+	return medication, nil
+}
+
+// Search pages through the approved catalog. Without an explicit sort, results are ordered by
+// relevance when there is a query and newest first otherwise.
+func (s *MedicationService) Search(ctx context.Context, query *dto.MedicationSearchQuery) (*dto.MedicationListResponse, error) {
+	filter, err := medicationSearchFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := filter.Limit
+	filter.Limit++ // one extra row tells whether there is a next page
+
+	hits, total, err := s.medicationRepo.Search(ctx, *filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search medications: %w", err)
+	}
+
+	response := &dto.MedicationListResponse{
+		Items: make([]*dto.MedicationResponse, 0, pageSize),
+		Total: total,
+	}
+	if len(hits) > pageSize {
+		hits = hits[:pageSize]
+		last := hits[len(hits)-1]
+		cursor, err := encodeMedicationCursor(filter.Sort, filter.Descending, &entity2.MedicationCursor{Key: last.SortKey, ID: last.ID})
+		if err != nil {
+			return nil, err
+		}
+		response.NextCursor = &cursor
+	}
+	for _, hit := range hits {
+		response.Items = append(response.Items, mapper.MedicationFromEntity(&hit.Medication))
+	}
+
+	return response, nil
+}
+
+// CanEdit reports whether the user may change a catalog entry: its owner for a private entry,
+// catalog managers for a shared one
+func CanEdit(medication *dto.MedicationResponse, userID uuid.UUID, role shared.Role) bool {
+	if medication.Visibility == shared.VisibilityPrivate {
+		return medication.OwnerID != nil && *medication.OwnerID == userID
+	}
+	return role.CanManageCatalog()
+}
+
+func isVisible(medication *entity2.Medication, userID uuid.UUID, role shared.Role) bool {
+	if medication.Visibility == shared.VisibilityPrivate {
+		return medication.OwnerID != nil && *medication.OwnerID == userID
+	}
+	if medication.Status == shared.MedicationApproved || role.CanManageCatalog() {
+		return true
+	}
+	return medication.SubmittedBy != nil && *medication.SubmittedBy == userID
+}
+
+const (
+	defaultMedicationPageSize = 20
+	maxMedicationPageSize     = 100
+)
+
+const maxExternalCodeLength = 64
+
+const maxBarcodesPerMedication = 20
+
+const maxDuplicateCandidates = 10
+
+var medicationForms = map[string]bool{"tablet": true, "capsule": true, "syrup": true, "drop": true, "injection": true}
+
+// normalizeMedicationCreate trims the request, turns blank optional fields into absent ones and fills in default units
+func normalizeMedicationCreate(req *dto.MedicationCreateRequest) {
+	if req.Visibility == "" {
+		req.Visibility = shared.VisibilityShared
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Form = strings.TrimSpace(req.Form)
+	req.Description = trimmedOrNil(req.Description)
+	req.Manufacturer = trimmedOrNil(req.Manufacturer)
+	req.ExternalCode = trimmedOrNil(req.ExternalCode)
+	req.Barcodes = normalizeBarcodes(req.Barcodes)
+	// Older clients send the strength in mg and the package size as pills per box
+	if req.Strength == 0 && req.StrengthMg != nil {
+		req.Strength, req.StrengthUnit = *req.StrengthMg, shared.UnitMilligram
+	}
+	if req.PackageSize == 0 && req.PillsPerBox != nil {
+		req.PackageSize = *req.PillsPerBox
+	}
+	req.StrengthUnit = shared.DoseUnit(strings.ToLower(strings.TrimSpace(string(req.StrengthUnit))))
+	req.PackageUnit = shared.DoseUnit(strings.ToLower(strings.TrimSpace(string(req.PackageUnit))))
+	if req.StrengthUnit == "" {
+		req.StrengthUnit = shared.UnitMilligram
+	}
+	if req.PackageUnit == "" {
+		req.PackageUnit = shared.DefaultPackageUnit(req.Form)
+	}
+}
+
+// validateMedicationCreate enforces the rules declared on dto.MedicationCreateRequest and
+// returns every violation, so importers can report them all at once
+func validateMedicationCreate(req *dto.MedicationCreateRequest) []string {
+	var problems []string
+	if len(req.Name) < 2 {
+		problems = append(problems, "name must be at least 2 characters")
+	}
+	if req.Description != nil && len(*req.Description) < 2 {
+		problems = append(problems, "description must be at least 2 characters")
+	}
+	if !medicationForms[req.Form] {
+		problems = append(problems, fmt.Sprintf("form must be one of tablet, capsule, syrup, drop, injection, got %q", req.Form))
+	}
+	problems = append(problems, validateMedicationUnits(req.Strength, req.StrengthUnit, req.PackageSize, req.PackageUnit)...)
+	if !isValidMealRelation(req.MealRelation) {
+		problems = append(problems, fmt.Sprintf("meal_relation must be one of before_meal, after_meal, with_meal, irrelevant, got %q", req.MealRelation))
+	}
+	if req.ExternalCode != nil && len(*req.ExternalCode) > maxExternalCodeLength {
+		problems = append(problems, fmt.Sprintf("external_code must be at most %d characters", maxExternalCodeLength))
+	}
+	problems = append(problems, validateBarcodes(req.Barcodes)...)
+	switch req.Visibility {
+	case shared.VisibilityShared, shared.VisibilityPrivate:
+	default:
+		problems = append(problems, fmt.Sprintf("visibility must be shared or private, got %q", req.Visibility))
+	}
+	return problems
+}
+
+func validateMedicationUnits(strength float32, strengthUnit shared.DoseUnit, packageSize float32, packageUnit shared.DoseUnit) []string {
+	var problems []string
+	if strength <= 0 {
+		problems = append(problems, "strength must be greater than 0")
+	}
+	if !strengthUnit.IsStrengthUnit() {
+		problems = append(problems, fmt.Sprintf("strength_unit must be one of mg, mcg, g, iu, got %q", strengthUnit))
+	}
+	if packageSize <= 0 {
+		problems = append(problems, "package_size must be greater than 0")
+	}
+	if !packageUnit.IsPackageUnit() {
+		problems = append(problems, fmt.Sprintf("package_unit must be one of units, ml, drops, puffs, g, got %q", packageUnit))
+	}
+	return problems
+}
+
+// normalizeBarcodes turns valid codes into 14-digit GTINs and drops blanks and repeats; invalid codes
+// are kept as given for validateBarcodes to report
+func normalizeBarcodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		if gtin, err := shared.NormalizeGTIN(code); err == nil {
+			code = gtin
+		}
+		if !slices.Contains(normalized, code) {
+			normalized = append(normalized, code)
+		}
+	}
+	return normalized
+}
+
+func validateBarcodes(codes []string) []string {
+	var problems []string
+	if len(codes) > maxBarcodesPerMedication {
+		problems = append(problems, fmt.Sprintf("at most %d barcodes are allowed", maxBarcodesPerMedication))
+	}
+	for _, code := range codes {
+		if _, err := shared.NormalizeGTIN(code); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// barcodeConflicts reports the codes another entry of the same catalog already carries: the shared
+// catalog when ownerID is nil, the owner's private entries otherwise. excludeID is the entry being changed.
+func barcodeConflicts(ctx context.Context, medicationRepo MedicationRepository, codes []string, ownerID *uuid.UUID, excludeID uuid.UUID) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	medications, err := medicationRepo.ListByBarcodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up barcodes: %w", err)
+	}
+
+	var conflicts []string
+	for _, medication := range medications {
+		sameCatalog := medication.OwnerID == nil && ownerID == nil ||
+			medication.OwnerID != nil && ownerID != nil && *medication.OwnerID == *ownerID
+		if medication.ID == excludeID || !sameCatalog {
+			continue
+		}
+		for _, code := range medication.Barcodes {
+			if slices.Contains(codes, code) {
+				conflicts = append(conflicts, fmt.Sprintf("barcode %s is already used by medication %q", code, medication.Name))
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func isValidMealRelation(relation shared.MealRelation) bool {
+	switch relation {
+	case shared.MealBefore, shared.MealAfter, shared.MealWith, shared.MealIrregular:
+		return true
+	default:
+		return false
+	}
+}
+
+// medicationSearchFilter validates the query and resolves its sort order and cursor
+func medicationSearchFilter(query *dto.MedicationSearchQuery) (*entity2.MedicationSearchFilter, error) {
+	filter := &entity2.MedicationSearchFilter{
+		ViewerID:     query.ViewerID,
+		Visibility:   query.Visibility,
+		Query:        strings.TrimSpace(query.Query),
+		Manufacturer: strings.TrimSpace(query.Manufacturer),
+		MealRelation: query.MealRelation,
+		Limit:        query.Limit,
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMedicationPageSize
+	}
+	if filter.Limit > maxMedicationPageSize {
+		filter.Limit = maxMedicationPageSize
+	}
+
+	for _, form := range query.Forms {
+		if !medicationForms[form] {
+			return nil, fmt.Errorf("invalid form: %s", form)
+		}
+		filter.Forms = append(filter.Forms, form)
+	}
+	if filter.MealRelation != "" && !isValidMealRelation(filter.MealRelation) {
+		return nil, fmt.Errorf("invalid meal relation: %s", filter.MealRelation)
+	}
+	switch filter.Visibility {
+	case "", shared.VisibilityShared, shared.VisibilityPrivate:
+	default:
+		return nil, fmt.Errorf("invalid visibility: %s", filter.Visibility)
+	}
+
+	if query.StrengthMin != nil {
+		value := float32(*query.StrengthMin)
+		filter.StrengthMin = &value
+	}
+	if query.StrengthMax != nil {
+		value := float32(*query.StrengthMax)
+		filter.StrengthMax = &value
+	}
+	if filter.StrengthMin != nil && filter.StrengthMax != nil && *filter.StrengthMin > *filter.StrengthMax {
+		return nil, fmt.Errorf("strength_min must not be greater than strength_max")
+	}
+
+	// "-field" sorts descending; relevance is always best match first
+	sort := query.Sort
+	switch {
+	case sort == "" && filter.Query != "":
+		sort = string(shared.SortByRelevance)
+	case sort == "":
+		sort = "-" + string(shared.SortByCreatedAt)
+	}
+	filter.Descending = strings.HasPrefix(sort, "-")
+	filter.Sort = shared.MedicationSort(strings.TrimPrefix(sort, "-"))
+
+	switch filter.Sort {
+	case shared.SortByRelevance:
+		if filter.Query == "" {
+			return nil, fmt.Errorf("sorting by relevance requires a search query")
+		}
+		filter.Descending = true
+	case shared.SortByName, shared.SortByStrength, shared.SortByCreatedAt:
+	default:
+		return nil, fmt.Errorf("invalid sort field: %s", filter.Sort)
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeMedicationCursor(query.Cursor, filter.Sort, filter.Descending)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+// medicationCursorToken is the opaque next_cursor value; it remembers the order it was issued for
+type medicationCursorToken struct {
+	Sort       shared.MedicationSort `json:"s"`
+	Descending bool                  `json:"d,omitempty"`
+	Key        string                `json:"k"`
+	ID         uuid.UUID             `json:"id"`
+}
+
+func encodeMedicationCursor(sort shared.MedicationSort, descending bool, cursor *entity2.MedicationCursor) (string, error) {
+	data, err := json.Marshal(medicationCursorToken{Sort: sort, Descending: descending, Key: cursor.Key, ID: cursor.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeMedicationCursor(raw string, sort shared.MedicationSort, descending bool) (*entity2.MedicationCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var token medicationCursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if token.Sort != sort || token.Descending != descending {
+		return nil, fmt.Errorf("cursor does not match the requested sort order")
+	}
+	return &entity2.MedicationCursor{Key: token.Key, ID: token.ID}, nil
+}
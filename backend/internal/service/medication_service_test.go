@@ -0,0 +1,118 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/shared"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckMergeCompatible(t *testing.T) {
+	tablet := func(strength float32, unit shared.DoseUnit) *entity2.Medication {
+		return &entity2.Medication{Form: "tablet", Strength: strength, StrengthUnit: unit, PackageUnit: shared.UnitCount}
+	}
+	syrup := &entity2.Medication{Form: "syrup", Strength: 50, StrengthUnit: shared.UnitMilligram, PackageUnit: shared.UnitMillilitre}
+	capsule := &entity2.Medication{Form: "capsule", Strength: 500, StrengthUnit: shared.UnitMilligram, PackageUnit: shared.UnitCount}
+
+	tests := []struct {
+		name           string
+		source, target *entity2.Medication
+		wantErr        bool
+	}{
+		{name: "same strength", source: tablet(500, shared.UnitMilligram), target: tablet(500, shared.UnitMilligram)},
+		{name: "same mass in other units", source: tablet(0.5, shared.UnitGram), target: tablet(500, shared.UnitMilligram)},
+		{name: "same iu strength", source: tablet(1000, shared.UnitInternational), target: tablet(1000, shared.UnitInternational)},
+		{name: "other strength", source: tablet(250, shared.UnitMilligram), target: tablet(500, shared.UnitMilligram), wantErr: true},
+		{name: "iu into mg", source: tablet(500, shared.UnitInternational), target: tablet(500, shared.UnitMilligram), wantErr: true},
+		{name: "syrup into tablet", source: syrup, target: tablet(50, shared.UnitMilligram), wantErr: true},
+		{name: "capsule into tablet", source: capsule, target: tablet(500, shared.UnitMilligram), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := checkMergeCompatible(tt.source, tt.target); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkMergeCompatible() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeMedicationCreateAcceptsLegacyFields(t *testing.T) {
+	strengthMg, pillsPerBox := float32(500), float32(20)
+	req := &dto.MedicationCreateRequest{Name: "Paracetamol", Form: "tablet", MealRelation: shared.MealAfter, StrengthMg: &strengthMg, PillsPerBox: &pillsPerBox}
+
+	normalizeMedicationCreate(req)
+	if req.Strength != 500 || req.StrengthUnit != shared.UnitMilligram || req.PackageSize != 20 || req.PackageUnit != shared.UnitCount {
+		t.Fatalf("normalizeMedicationCreate() = %g %s, %g %s, want 500 mg, 20 units", req.Strength, req.StrengthUnit, req.PackageSize, req.PackageUnit)
+	}
+	if problems := validateMedicationCreate(req); len(problems) > 0 {
+		t.Errorf("validateMedicationCreate() = %v, want a valid request", problems)
+	}
+}
+
+// memoryMedicationRepository implements the lookups MedicationService.Update needs; other methods panic
+type memoryMedicationRepository struct {
+	MedicationRepository
+	medications map[uuid.UUID]*entity2.Medication
+}
+
+func (r *memoryMedicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity2.Medication, error) {
+	medication, ok := r.medications[id]
+	if !ok {
+		return nil, nil
+	}
+	// Return a copy, like a row read from the database
+	copied := *medication
+	return &copied, nil
+}
+
+func (r *memoryMedicationRepository) GetByExternalCode(ctx context.Context, code string, ownerID *uuid.UUID) (*entity2.Medication, error) {
+	for _, medication := range r.medications {
+		if medication.ExternalCode != nil && *medication.ExternalCode == code {
+			return medication, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryMedicationRepository) ListByBarcodes(ctx context.Context, codes []string) ([]*entity2.Medication, error) {
+	var medications []*entity2.Medication
+	for _, medication := range r.medications {
+		if slices.ContainsFunc(medication.Barcodes, func(code string) bool { return slices.Contains(codes, code) }) {
+			medications = append(medications, medication)
+		}
+	}
+	return medications, nil
+}
+
+func (r *memoryMedicationRepository) Update(ctx context.Context, medication *entity2.Medication) error {
+	r.medications[medication.ID] = medication
+	return nil
+}
+
+func TestUpdateReportsTypedErrors(t *testing.T) {
+	code := "EXT-1"
+	edited := &entity2.Medication{ID: uuid.New(), Name: "Paracetamol", Form: "tablet", Strength: 500, StrengthUnit: shared.UnitMilligram, PackageSize: 20, PackageUnit: shared.UnitCount}
+	other := &entity2.Medication{ID: uuid.New(), Name: "Ibuprofen", Form: "tablet", Strength: 400, StrengthUnit: shared.UnitMilligram, PackageSize: 20, PackageUnit: shared.UnitCount,
+		ExternalCode: &code, Barcodes: []string{"04006381333931"}}
+	repo := &memoryMedicationRepository{medications: map[uuid.UUID]*entity2.Medication{edited.ID: edited, other.ID: other}}
+	s := NewMedicationService(repo, nil, nil, NewAuditService(discardAuditRepository{}))
+
+	zero := float32(0)
+	if _, err := s.Update(context.Background(), edited.ID, &dto.MedicationUpdateRequest{Strength: &zero}); !errors.As(err, new(*MedicationValidationError)) {
+		t.Errorf("Update(strength 0) error = %v, want a MedicationValidationError", err)
+	}
+	if _, err := s.Update(context.Background(), edited.ID, &dto.MedicationUpdateRequest{ExternalCode: &code}); !errors.As(err, new(*MedicationConflictError)) {
+		t.Errorf("Update(taken external code) error = %v, want a MedicationConflictError", err)
+	}
+	barcodes := []string{"4006381333931"}
+	if _, err := s.Update(context.Background(), edited.ID, &dto.MedicationUpdateRequest{Barcodes: &barcodes}); !errors.As(err, new(*MedicationConflictError)) {
+		t.Errorf("Update(taken barcode) error = %v, want a MedicationConflictError", err)
+	}
+	if got, err := s.Update(context.Background(), uuid.New(), &dto.MedicationUpdateRequest{}); got != nil || err != nil {
+		t.Errorf("Update(unknown id) = %v, %v, want nil, nil", got, err)
+	}
+}
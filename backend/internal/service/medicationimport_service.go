@@ -0,0 +1,393 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportRows caps the size of a single catalog import
+const MaxImportRows = 10000
+
+// Import file formats
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// medicationImportColumns are the CSV header names, matching the JSON fields of dto.MedicationCreateRequest
+var medicationImportColumns = []string{"name", "description", "manufacturer", "form", "strength", "strength_unit", "package_size", "package_unit", "meal_relation", "external_code", "barcodes"}
+
+var requiredImportColumns = []string{"name", "form", "strength", "package_size", "meal_relation"}
+
+type MedicationImportService struct {
+	medicationRepo MedicationRepository
+	auditService   *AuditService
+}
+
+func NewMedicationImportService(medicationRepo MedicationRepository, auditService *AuditService) *MedicationImportService {
+	return &MedicationImportService{
+		medicationRepo: medicationRepo,
+		auditService:   auditService,
+	}
+}
+
+// importRow is one parsed input row; parse errors are kept so they can be reported with the row
+type importRow struct {
+	number int
+	req    dto.MedicationCreateRequest
+	errors []string
+}
+
+// Import upserts catalog entries from a CSV or JSON file. Rows are matched to existing entries by
+// external_code, then by name. Imported entries are approved; blank optional fields keep the value
+// already in the catalog. Every row gets a result, and one bad row does not stop the others.
+// With dryRun nothing is written and the report shows what would happen.
+func (s *MedicationImportService) Import(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.MedicationImportReport, error) {
+	var rows []*importRow
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = parseCSVImport(r)
+	case ImportFormatJSON:
+		rows, err = parseJSONImport(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q, expected csv or json", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.MedicationImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]*dto.MedicationImportRowResult, 0, len(rows)),
+	}
+
+	// Later rows must not silently overwrite earlier rows of the same file
+	seenNames := make(map[string]int)
+	seenCodes := make(map[string]int)
+	seenBarcodes := make(map[string]int)
+
+	for _, row := range rows {
+		normalizeMedicationCreate(&row.req)
+		result := &dto.MedicationImportRowResult{
+			Row:          row.number,
+			Name:         row.req.Name,
+			ExternalCode: row.req.ExternalCode,
+		}
+
+		// Rows that could not be parsed are not validated further; the values would only be zero
+		problems := row.errors
+		if len(problems) == 0 {
+			problems = validateMedicationCreate(&row.req)
+		}
+		if row.req.Visibility == shared.VisibilityPrivate {
+			problems = append(problems, "imports only write to the shared catalog")
+		}
+		if first, ok := seenNames[strings.ToLower(row.req.Name)]; ok && row.req.Name != "" {
+			problems = append(problems, fmt.Sprintf("duplicate of row %d (same name)", first))
+		}
+		if row.req.ExternalCode != nil {
+			if first, ok := seenCodes[*row.req.ExternalCode]; ok {
+				problems = append(problems, fmt.Sprintf("duplicate of row %d (same external_code)", first))
+			}
+		}
+		for _, code := range row.req.Barcodes {
+			if first, ok := seenBarcodes[code]; ok {
+				problems = append(problems, fmt.Sprintf("barcode %s is also used by row %d", code, first))
+			}
+		}
+
+		if len(problems) == 0 {
+			seenNames[strings.ToLower(row.req.Name)] = row.number
+			if row.req.ExternalCode != nil {
+				seenCodes[*row.req.ExternalCode] = row.number
+			}
+			for _, code := range row.req.Barcodes {
+				seenBarcodes[code] = row.number
+			}
+			problems = s.importRow(ctx, &row.req, dryRun, result)
+		}
+
+		if len(problems) > 0 {
+			result.Action = shared.ImportError
+			result.Errors = problems
+		}
+
+		switch result.Action {
+		case shared.ImportCreate:
+			report.Created++
+		case shared.ImportUpdate:
+			report.Updated++
+		case shared.ImportUnchanged:
+			report.Unchanged++
+		case shared.ImportError:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	return report, nil
+}
+
+// importRow creates or updates the catalog entry for one valid row and fills in result
+func (s *MedicationImportService) importRow(ctx context.Context, req *dto.MedicationCreateRequest, dryRun bool, result *dto.MedicationImportRowResult) []string {
+	existing, problems := s.match(ctx, req)
+	if len(problems) > 0 {
+		return problems
+	}
+
+	excludeID := uuid.Nil
+	if existing != nil {
+		excludeID = existing.ID
+	}
+	conflicts, err := barcodeConflicts(ctx, s.medicationRepo, req.Barcodes, nil, excludeID)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+
+	if existing == nil {
+		medication := mapper.MedicationToEntity(req)
+		medication.Status = shared.MedicationApproved
+		result.Action = shared.ImportCreate
+		if dryRun {
+			return nil
+		}
+
+		if err := s.medicationRepo.Create(ctx, medication); err != nil {
+			return []string{fmt.Sprintf("failed to create medication: %v", err)}
+		}
+		if err := s.auditService.Record(ctx, shared.AuditCreate, shared.AuditEntityMedication, medication.ID, nil, nil, medication); err != nil {
+			return []string{err.Error()}
+		}
+		result.MedicationID = &medication.ID
+		return nil
+	}
+
+	result.MedicationID = &existing.ID
+	before := *existing
+	applyImportRow(existing, req)
+	if sameMedication(&before, existing) {
+		result.Action = shared.ImportUnchanged
+		return nil
+	}
+
+	result.Action = shared.ImportUpdate
+	if dryRun {
+		return nil
+	}
+
+	if err := s.medicationRepo.Update(ctx, existing); err != nil {
+		return []string{fmt.Sprintf("failed to update medication: %v", err)}
+	}
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedication, existing.ID, existing.SubmittedBy, &before, existing); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// match finds the entry a row updates, or nil if the row creates a new one
+func (s *MedicationImportService) match(ctx context.Context, req *dto.MedicationCreateRequest) (*entity2.Medication, []string) {
+	byName, err := s.medicationRepo.GetByName(ctx, req.Name, nil)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("failed to look up medication: %v", err)}
+	}
+
+	if req.ExternalCode != nil {
+		// Private entries may hold the same code; imports only match the shared catalog
+		byCode, err := s.medicationRepo.GetByExternalCode(ctx, *req.ExternalCode, nil)
+		if err != nil {
+			return nil, []string{fmt.Sprintf("failed to look up medication: %v", err)}
+		}
+		if byCode != nil {
+			if byName != nil && byName.ID != byCode.ID {
+				return nil, []string{fmt.Sprintf("name %q is already used by another medication", req.Name)}
+			}
+			return byCode, nil
+		}
+		if byName != nil && byName.ExternalCode != nil {
+			return nil, []string{fmt.Sprintf("name %q is already used by the medication with external_code %s", req.Name, *byName.ExternalCode)}
+		}
+	}
+
+	return byName, nil
+}
+
+// applyImportRow copies the row onto an existing entry; absent optional fields keep their value
+func applyImportRow(med *entity2.Medication, req *dto.MedicationCreateRequest) {
+	med.Name = req.Name
+	med.Form = req.Form
+	med.Strength = req.Strength
+	med.StrengthUnit = req.StrengthUnit
+	med.PackageSize = req.PackageSize
+	med.PackageUnit = req.PackageUnit
+	med.MealRelation = req.MealRelation
+	if req.Description != nil {
+		med.Description = req.Description
+	}
+	if req.Manufacturer != nil {
+		med.Manufacturer = req.Manufacturer
+	}
+	if req.ExternalCode != nil {
+		med.ExternalCode = req.ExternalCode
+	}
+	if len(req.Barcodes) > 0 {
+		med.Barcodes = req.Barcodes
+	}
+}
+
+func sameMedication(a, b *entity2.Medication) bool {
+	return a.Name == b.Name &&
+		a.Form == b.Form &&
+		a.Strength == b.Strength &&
+		a.StrengthUnit == b.StrengthUnit &&
+		a.PackageSize == b.PackageSize &&
+		a.PackageUnit == b.PackageUnit &&
+		a.MealRelation == b.MealRelation &&
+		equalStringPtr(a.Description, b.Description) &&
+		equalStringPtr(a.Manufacturer, b.Manufacturer) &&
+		equalStringPtr(a.ExternalCode, b.ExternalCode) &&
+		slices.Equal(a.Barcodes, b.Barcodes)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// parseCSVImport reads a CSV file with a header row naming the columns; column order is free
+// and unknown columns are rejected. Row numbers are file line numbers, the header being line 1.
+func parseCSVImport(r io.Reader) ([]*importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(medicationImportColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, expected %s", name, strings.Join(medicationImportColumns, ", "))
+		}
+		if _, duplicate := columns[name]; duplicate {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing required CSV column %q", name)
+		}
+	}
+
+	var rows []*importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) >= MaxImportRows {
+			return nil, fmt.Errorf("too many rows, at most %d are allowed per import", MaxImportRows)
+		}
+
+		row := &importRow{number: line}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.errors = append(row.errors, parseErr.Err.Error())
+			rows = append(rows, row)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		optional := func(name string) *string {
+			if value := field(name); value != "" {
+				return &value
+			}
+			return nil
+		}
+		number := func(name string) float32 {
+			raw := field(name)
+			value, err := strconv.ParseFloat(raw, 32)
+			if err != nil {
+				row.errors = append(row.errors, fmt.Sprintf("%s must be a number, got %q", name, raw))
+			}
+			return float32(value)
+		}
+
+		row.req = dto.MedicationCreateRequest{
+			Name:         field("name"),
+			Description:  optional("description"),
+			Manufacturer: optional("manufacturer"),
+			Form:         field("form"),
+			Strength:     number("strength"),
+			StrengthUnit: shared.DoseUnit(field("strength_unit")),
+			PackageSize:  number("package_size"),
+			PackageUnit:  shared.DoseUnit(field("package_unit")),
+			MealRelation: shared.MealRelation(field("meal_relation")),
+			ExternalCode: optional("external_code"),
+			// Several barcodes share the column, separated by semicolons
+			Barcodes: strings.Split(field("barcodes"), ";"),
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseJSONImport reads a JSON array of objects shaped like dto.MedicationCreateRequest.
+// Row numbers count array elements from 1.
+func parseJSONImport(r io.Reader) ([]*importRow, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&elements); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of medications: %w", err)
+	}
+	if len(elements) > MaxImportRows {
+		return nil, fmt.Errorf("too many rows, at most %d are allowed per import", MaxImportRows)
+	}
+
+	rows := make([]*importRow, len(elements))
+	for i, element := range elements {
+		row := &importRow{number: i + 1}
+		decoder := json.NewDecoder(strings.NewReader(string(element)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.req); err != nil {
+			row.errors = append(row.errors, fmt.Sprintf("invalid row: %v", err))
+		}
+		rows[i] = row
+	}
+
+	return rows, nil
+}
@@ -4,19 +4,27 @@ import (
 	"backend/internal/core/dto"
 	entity2 "backend/internal/core/entity"
 	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type MedicationLogService struct {
-	medicationLogRepo MedicationLogRepository
+	medicationLogRepo  MedicationLogRepository
+	userMedicationRepo UserMedicationRepository
+	profileRepo        ProfileRepository
+	auditService       *AuditService
 }
 
-func NewMedicationLogService(medicationLogRepo MedicationLogRepository) *MedicationLogService {
+func NewMedicationLogService(medicationLogRepo MedicationLogRepository, userMedicationRepo UserMedicationRepository, profileRepo ProfileRepository, auditService *AuditService) *MedicationLogService {
 	return &MedicationLogService{
-		medicationLogRepo: medicationLogRepo,
+		medicationLogRepo:  medicationLogRepo,
+		userMedicationRepo: userMedicationRepo,
+		profileRepo:        profileRepo,
+		auditService:       auditService,
 	}
 }
 
@@ -31,7 +39,8 @@ func (s *MedicationLogService) GetByID(ctx context.Context, id uuid.UUID) (*dto.
 	return mapper.MedicationLogFromEntity(log), nil
 }
 
-func (s *MedicationLogService) MarkAsTaken(ctx context.Context, id uuid.UUID) error {
+// MarkAsTaken records the dose as taken by actorID, who is the patient or one of their caregivers
+func (s *MedicationLogService) MarkAsTaken(ctx context.Context, id, actorID uuid.UUID) error {
 	log, err := s.medicationLogRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get medication log: %w", err)
@@ -40,13 +49,24 @@ func (s *MedicationLogService) MarkAsTaken(ctx context.Context, id uuid.UUID) er
 		return fmt.Errorf("medication log not found with id: %s", id)
 	}
 
+	userMedication, err := s.userMedicationRepo.GetByID(ctx, log.UserMedicationID)
+	if err != nil {
+		return fmt.Errorf("failed to get user medication: %w", err)
+	}
+	if userMedication == nil {
+		return fmt.Errorf("user medication not found with id: %s", log.UserMedicationID)
+	}
+
+	before := *log
+	now := time.Now()
 	log.Taken = true
+	log.TakenBy = &actorID
+	log.TakenAt = &now
 
 	if err := s.medicationLogRepo.Update(ctx, log); err != nil {
 		return fmt.Errorf("failed to mark medication log as taken: %w", err)
 	}
-
-	return nil
+	return s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityMedicationLog, log.ID, &userMedication.UserID, &before, log)
 }
 
 func (s *MedicationLogService) GetByUserMedicationID(ctx context.Context, userMedicationID uuid.UUID) ([]*dto.MedicationLogResponse, error) {
@@ -63,25 +83,58 @@ func (s *MedicationLogService) GetByUserMedicationID(ctx context.Context, userMe
 	return responses, nil
 }
 
+// CreateLogsForUserMedication plans one log per schedule and day, starting on the day tracking
+// starts. Each log is timestamped with the slot's clock time in the profile's timezone, so a
+// morning dose at 07:30 stays at 07:30 local time across DST changes. The plan is audited as one
+// medication_log_batch event listing the log IDs.
 func (s *MedicationLogService) CreateLogsForUserMedication(ctx context.Context, um *entity2.UserMedication, durationDays int) error {
-	endDate := um.StartAt.AddDate(0, 0, durationDays)
+	profile, err := s.profileRepo.GetByID(ctx, um.ProfileID)
+	if err != nil {
+		return fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile == nil {
+		return fmt.Errorf("profile not found with id: %s", um.ProfileID)
+	}
 
-	for d := um.StartAt; d.Before(endDate); d = d.AddDate(0, 0, 1) {
+	loc, err := time.LoadLocation(profile.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid profile timezone %q: %w", profile.Timezone, err)
+	}
+	year, month, day := um.StartAt.In(loc).Date()
+
+	batch := &entity2.MedicationLogBatch{UserMedicationID: um.ID, LogIDs: []uuid.UUID{}}
+	for i := 0; i < durationDays; i++ {
 		for _, schedule := range um.Schedules {
+			timestamp, err := profile.ScheduledAt(loc, year, month, day+i, schedule.TimeSlot)
+			if err != nil {
+				return fmt.Errorf("failed to schedule %s dose: %w", schedule.TimeSlot, err)
+			}
+
 			log := &entity2.MedicationLog{
 				ID:               uuid.New(),
 				UserMedicationID: um.ID,
 				TimeSlot:         schedule.TimeSlot,
 				PlannedDose:      schedule.DoseAmount,
+				DoseUnit:         schedule.DoseUnit,
 				Taken:            false,
-				Timestamp:        d,
+				Timestamp:        timestamp,
 			}
 
 			if err := s.medicationLogRepo.Create(ctx, log); err != nil {
 				return fmt.Errorf("failed to create medication log: %w", err)
 			}
+			batch.LogIDs = append(batch.LogIDs, log.ID)
+			if batch.From.IsZero() || timestamp.Before(batch.From) {
+				batch.From = timestamp
+			}
+			if timestamp.After(batch.To) {
+				batch.To = timestamp
+			}
 		}
 	}
+	if len(batch.LogIDs) == 0 {
+		return nil
+	}
 
-	return nil
+	return s.auditService.Record(ctx, shared.AuditCreate, shared.AuditEntityMedicationLogBatch, um.ID, &um.UserID, nil, batch)
 }
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/shared"
+	"backend/internal/oidc"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type OIDCService struct {
+	providers    *oidc.Registry
+	stateRepo    OIDCStateRepository
+	identityRepo UserIdentityRepository
+	userRepo     UserRepository
+	profileRepo  ProfileRepository
+	authService  *AuthService
+}
+
+func NewOIDCService(providers *oidc.Registry, stateRepo OIDCStateRepository, identityRepo UserIdentityRepository, userRepo UserRepository, profileRepo ProfileRepository, authService *AuthService) *OIDCService {
+	return &OIDCService{
+		providers:    providers,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		profileRepo:  profileRepo,
+		authService:  authService,
+	}
+}
+
+func (s *OIDCService) Providers() []*dto.OIDCProviderResponse {
+	providers := s.providers.List()
+	responses := make([]*dto.OIDCProviderResponse, len(providers))
+	for i, provider := range providers {
+		responses[i] = &dto.OIDCProviderResponse{
+			Name:         provider.Name(),
+			DisplayName:  provider.DisplayName(),
+			AuthorizeURL: fmt.Sprintf("%s/api/auth/oidc/%s/authorize", config.AppBaseURL, provider.Name()),
+		}
+	}
+	return responses
+}
+
+// Begin stores a fresh state, nonce and PKCE verifier and returns the issuer URL to send the user to
+func (s *OIDCService) Begin(ctx context.Context, providerName string, deviceName *string) (string, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", fmt.Errorf("unknown identity provider: %s", providerName)
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	loginState := &entity2.OIDCLoginState{
+		StateHash:    auth.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		DeviceName:   deviceName,
+		ExpiresAt:    now.Add(config.OIDCStateTTL),
+		CreatedAt:    now,
+	}
+	if err := s.stateRepo.Create(ctx, loginState); err != nil {
+		return "", fmt.Errorf("failed to store login state: %w", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// Complete redeems the authorization code, links or creates the local account and logs it in
+func (s *OIDCService) Complete(ctx context.Context, providerName, code, state, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, fmt.Errorf("unknown identity provider: %s", providerName)
+	}
+
+	loginState, err := s.stateRepo.Consume(ctx, auth.HashToken(state))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load login state: %w", err)
+	}
+	if loginState == nil || loginState.Provider != providerName || !time.Now().Before(loginState.ExpiresAt) {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+
+	claims, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.LoginAs(ctx, user, loginState.DeviceName, userAgent, ipAddress)
+}
+
+// resolveUser finds the account for the external identity: first by linked subject, then by a
+// verified email address, and otherwise creates a new account. An existing account is only linked
+// once its own address is verified: whoever registered it unverified may not own the address and
+// would keep their password after the link.
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, claims *oidc.IDTokenClaims) (*entity2.User, error) {
+	now := time.Now()
+	email := optionalString(normalizeEmail(claims.Email))
+
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, providerName, claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, fmt.Errorf("linked account no longer exists")
+		}
+		if err := s.identityRepo.TouchLastLogin(ctx, identity.ID, email, now); err != nil {
+			log.Printf("failed to record identity login: %v", err)
+		}
+		return user, nil
+	}
+
+	// Unverified addresses are never used for linking, otherwise anyone able to
+	// register that address at the provider could take over the local account
+	if email == nil || !claims.IsEmailVerified() {
+		return nil, fmt.Errorf("identity provider did not return a verified email address")
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, *email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		user, err = s.createUser(ctx, *email, now)
+		if err != nil {
+			return nil, err
+		}
+	} else if user.VerifiedAt == nil {
+		return nil, fmt.Errorf("an account with this email address exists but is not verified: verify the address before signing in with an identity provider")
+	}
+
+	identity = &entity2.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Provider:    providerName,
+		Subject:     claims.Subject,
+		Email:       email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+// createUser registers an account for a first-time external login. It gets a random password
+// nobody knows; the user can set one through the password reset flow.
+func (s *OIDCService) createUser(ctx context.Context, email string, now time.Time) (*entity2.User, error) {
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &entity2.User{
+		ID:         uuid.New(),
+		Email:      email,
+		Password:   string(hashedPassword),
+		Role:       shared.RoleUser,
+		VerifiedAt: &now,
+		CreatedAt:  now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	if err := s.userRepo.MarkVerified(ctx, user.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if err := s.profileRepo.Create(ctx, newDefaultProfile(user)); err != nil {
+		return nil, fmt.Errorf("failed to create default profile: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/shared"
+	"backend/internal/oidc"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryUserRepository implements the user lookups resolveUser needs; other methods panic
+type memoryUserRepository struct {
+	UserRepository
+	users    map[uuid.UUID]*entity2.User
+	verified []uuid.UUID
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity2.User, error) {
+	return r.users[id], nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*entity2.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepository) MarkVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	r.verified = append(r.verified, id)
+	return nil
+}
+
+// memoryIdentityRepository keeps linked identities in a slice
+type memoryIdentityRepository struct {
+	identities []*entity2.UserIdentity
+}
+
+func (r *memoryIdentityRepository) Create(ctx context.Context, identity *entity2.UserIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memoryIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity2.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryIdentityRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity2.UserIdentity, error) {
+	return nil, nil
+}
+
+func (r *memoryIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, email *string, at time.Time) error {
+	return nil
+}
+
+func newTestOIDCService(users ...*entity2.User) (*OIDCService, *memoryUserRepository, *memoryIdentityRepository) {
+	userRepo := &memoryUserRepository{users: make(map[uuid.UUID]*entity2.User)}
+	for _, user := range users {
+		userRepo.users[user.ID] = user
+	}
+	identityRepo := &memoryIdentityRepository{}
+	return NewOIDCService(nil, nil, identityRepo, userRepo, nil, nil), userRepo, identityRepo
+}
+
+// testIDTokenClaims decodes the claims the way the provider does after verifying a token
+func testIDTokenClaims(t *testing.T, email string, emailVerified bool) *oidc.IDTokenClaims {
+	t.Helper()
+	raw, _ := json.Marshal(map[string]interface{}{"sub": "subject-1", "email": email, "email_verified": emailVerified})
+	claims := &oidc.IDTokenClaims{}
+	if err := json.Unmarshal(raw, claims); err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+	return claims
+}
+
+func TestResolveUserRefusesUnverifiedAccount(t *testing.T) {
+	// Someone registered the victim's address with a password of their choosing and never verified it
+	squatter := &entity2.User{ID: uuid.New(), Email: "victim@example.com", Password: "attacker-hash", Role: shared.RoleUser}
+	s, userRepo, identityRepo := newTestOIDCService(squatter)
+
+	user, err := s.resolveUser(context.Background(), "test", testIDTokenClaims(t, "victim@example.com", true))
+	if err == nil {
+		t.Fatalf("resolveUser() = %+v, want it to refuse linking into an unverified account", user)
+	}
+	if len(identityRepo.identities) != 0 {
+		t.Error("resolveUser() linked the identity to the unverified account")
+	}
+	if len(userRepo.verified) != 0 || squatter.VerifiedAt != nil {
+		t.Error("resolveUser() marked the unverified account as verified")
+	}
+}
+
+func TestResolveUserLinksVerifiedAccount(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	owner := &entity2.User{ID: uuid.New(), Email: "owner@example.com", Role: shared.RoleUser, VerifiedAt: &verifiedAt}
+	s, _, identityRepo := newTestOIDCService(owner)
+
+	user, err := s.resolveUser(context.Background(), "test", testIDTokenClaims(t, "Owner@Example.com", true))
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+	if user.ID != owner.ID {
+		t.Fatalf("resolveUser() = %s, want the verified account %s", user.ID, owner.ID)
+	}
+	if len(identityRepo.identities) != 1 || identityRepo.identities[0].UserID != owner.ID {
+		t.Fatalf("resolveUser() linked %+v, want one identity for the account", identityRepo.identities)
+	}
+
+	// The next login finds the account through the linked subject
+	again, err := s.resolveUser(context.Background(), "test", testIDTokenClaims(t, "other@example.com", false))
+	if err != nil || again.ID != owner.ID {
+		t.Errorf("resolveUser() by linked subject = %v, %v, want the account %s", again, err, owner.ID)
+	}
+}
+
+func TestResolveUserRequiresVerifiedProviderEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	owner := &entity2.User{ID: uuid.New(), Email: "owner@example.com", Role: shared.RoleUser, VerifiedAt: &verifiedAt}
+	s, _, identityRepo := newTestOIDCService(owner)
+
+	if _, err := s.resolveUser(context.Background(), "test", testIDTokenClaims(t, "owner@example.com", false)); err == nil {
+		t.Fatal("resolveUser() linked an account through an address the provider did not verify")
+	}
+	if len(identityRepo.identities) != 0 {
+		t.Error("resolveUser() created an identity")
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/mailer"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+type PasswordResetService struct {
+	userRepo                UserRepository
+	passwordResetRepo       PasswordResetRepository
+	sessionRepo             SessionRepository
+	personalAccessTokenRepo PersonalAccessTokenRepository
+	mailer                  mailer.Mailer
+}
+
+func NewPasswordResetService(userRepo UserRepository, passwordResetRepo PasswordResetRepository, sessionRepo SessionRepository, personalAccessTokenRepo PersonalAccessTokenRepository, mailer mailer.Mailer) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:                userRepo,
+		passwordResetRepo:       passwordResetRepo,
+		sessionRepo:             sessionRepo,
+		personalAccessTokenRepo: personalAccessTokenRepo,
+		mailer:                  mailer,
+	}
+}
+
+// RequestReset emails a reset link; unknown addresses are ignored so callers cannot probe for accounts
+func (s *PasswordResetService) RequestReset(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resetToken := &entity2.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: now.Add(config.PasswordResetTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.passwordResetRepo.Create(ctx, resetToken); err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppBaseURL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your DoseLog password",
+		Body: fmt.Sprintf("We received a request to reset your DoseLog password.\n\n"+
+			"Open the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.", config.PasswordResetTTL, link),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password, logs out every session and revokes
+// every personal access token
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	if len(req.NewPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	resetToken, err := s.passwordResetRepo.GetByTokenHash(ctx, auth.HashToken(req.Token))
+	if err != nil {
+		return fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	now := time.Now()
+	if resetToken == nil || resetToken.UsedAt != nil || !now.Before(resetToken.ExpiresAt) {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	consumed, err := s.passwordResetRepo.MarkUsed(ctx, resetToken.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+	if !consumed {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, resetToken.UserID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.passwordResetRepo.InvalidateByUserID(ctx, resetToken.UserID, now); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, resetToken.UserID, nil, now); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.personalAccessTokenRepo.RevokeAllByUserID(ctx, resetToken.UserID, now); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tokenPrefixLength is how much of a raw token is kept in clear text so users can tell tokens apart
+const tokenPrefixLength = 12
+
+type PersonalAccessTokenService struct {
+	tokenRepo PersonalAccessTokenRepository
+	userRepo  UserRepository
+}
+
+func NewPersonalAccessTokenService(tokenRepo PersonalAccessTokenRepository, userRepo UserRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Create issues a new token; the raw value is returned here and never stored
+func (s *PersonalAccessTokenService) Create(ctx context.Context, userID uuid.UUID, req *dto.PersonalAccessTokenCreateRequest) (*dto.PersonalAccessTokenCreatedResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("token name must be between 1 and 100 characters")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, fmt.Errorf("unknown scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	raw := auth.PersonalAccessTokenPrefix + secret
+
+	now := time.Now()
+	token := &entity2.PersonalAccessToken{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: raw[:tokenPrefixLength],
+		TokenHash:   auth.HashToken(raw),
+		Scopes:      scopes,
+		CreatedAt:   now,
+	}
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 || *req.ExpiresInDays > 365 {
+			return nil, fmt.Errorf("expires_in_days must be between 1 and 365")
+		}
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	return &dto.PersonalAccessTokenCreatedResponse{
+		PersonalAccessTokenResponse: *mapper.PersonalAccessTokenFromEntity(token),
+		Token:                       raw,
+	}, nil
+}
+
+func (s *PersonalAccessTokenService) List(ctx context.Context, userID uuid.UUID) ([]*dto.PersonalAccessTokenResponse, error) {
+	tokens, err := s.tokenRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+
+	responses := make([]*dto.PersonalAccessTokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = mapper.PersonalAccessTokenFromEntity(token)
+	}
+
+	return responses, nil
+}
+
+func (s *PersonalAccessTokenService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	token, err := s.tokenRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get personal access token: %w", err)
+	}
+	if token == nil || token.UserID != userID || token.RevokedAt != nil {
+		return fmt.Errorf("personal access token not found with id: %s", id)
+	}
+
+	if err := s.tokenRepo.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateAccessToken implements auth.TokenValidator. It returns nil for unknown, expired or revoked tokens.
+func (s *PersonalAccessTokenService) ValidateAccessToken(ctx context.Context, raw, ip string) (*auth.TokenIdentity, error) {
+	token, err := s.tokenRepo.GetByTokenHash(ctx, auth.HashToken(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+	now := time.Now()
+	if token == nil || !token.IsActive(now) {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	// Usage tracking is best effort and must not fail the request
+	if err := s.tokenRepo.TouchLastUsed(ctx, token.ID, ip, now); err != nil {
+		log.Printf("failed to record personal access token use: %v", err)
+	}
+
+	return &auth.TokenIdentity{
+		TokenID: token.ID,
+		UserID:  user.ID,
+		Role:    user.Role,
+		Scopes:  token.Scopes,
+	}, nil
+}
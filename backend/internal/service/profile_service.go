@@ -0,0 +1,250 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxProfileNameLength = 100
+
+var (
+	// localePattern accepts BCP 47 style tags: a language with optional script, region or variant subtags
+	localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+	// clockPattern matches a 24-hour wall-clock time, the format slot times are stored in
+	clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+)
+
+type ProfileService struct {
+	profileRepo ProfileRepository
+	userRepo    UserRepository
+}
+
+func NewProfileService(profileRepo ProfileRepository, userRepo UserRepository) *ProfileService {
+	return &ProfileService{
+		profileRepo: profileRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// GetByID returns the profile or nil if it does not exist; callers check ownership
+func (s *ProfileService) GetByID(ctx context.Context, id uuid.UUID) (*dto.ProfileResponse, error) {
+	profile, err := s.profileRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile == nil {
+		return nil, nil
+	}
+	return mapper.ProfileFromEntity(profile), nil
+}
+
+// Default returns the user's default profile, creating it for accounts that predate profiles
+func (s *ProfileService) Default(ctx context.Context, userID uuid.UUID) (*dto.ProfileResponse, error) {
+	profile, err := s.defaultProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.ProfileFromEntity(profile), nil
+}
+
+func (s *ProfileService) List(ctx context.Context, userID uuid.UUID) ([]*dto.ProfileResponse, error) {
+	// Make sure the default profile shows up even if it has never been used
+	if _, err := s.defaultProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.profileRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	responses := make([]*dto.ProfileResponse, len(profiles))
+	for i, profile := range profiles {
+		responses[i] = mapper.ProfileFromEntity(profile)
+	}
+
+	return responses, nil
+}
+
+func (s *ProfileService) Create(ctx context.Context, userID uuid.UUID, req *dto.ProfileCreateRequest) (*dto.ProfileResponse, error) {
+	if err := validateProfile(req.Name, req.Kind); err != nil {
+		return nil, err
+	}
+	if err := validateProfileSettings(&req.ProfileSettings); err != nil {
+		return nil, err
+	}
+
+	// Dependents start out with the account holder's timezone, locale and slot times
+	base, err := s.defaultProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := mapper.ProfileToEntity(userID, req, base)
+	profile.Name = strings.TrimSpace(profile.Name)
+
+	if err := s.profileRepo.Create(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	return mapper.ProfileFromEntity(profile), nil
+}
+
+func (s *ProfileService) Update(ctx context.Context, userID, id uuid.UUID, req *dto.ProfileUpdateRequest) (*dto.ProfileResponse, error) {
+	profile, err := s.ownedProfile(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateProfileSettings(&req.ProfileSettings); err != nil {
+		return nil, err
+	}
+
+	mapper.UpdateProfileEntity(profile, req)
+	profile.Name = strings.TrimSpace(profile.Name)
+	if err := validateProfile(profile.Name, profile.Kind); err != nil {
+		return nil, err
+	}
+
+	if err := s.profileRepo.Update(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return mapper.ProfileFromEntity(profile), nil
+}
+
+// Delete removes a dependent profile together with its tracked medications and logs
+func (s *ProfileService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	profile, err := s.ownedProfile(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if profile.IsDefault {
+		return fmt.Errorf("the default profile cannot be deleted")
+	}
+
+	if err := s.profileRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ProfileService) ownedProfile(ctx context.Context, userID, id uuid.UUID) (*entity2.Profile, error) {
+	profile, err := s.profileRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile == nil || profile.UserID != userID {
+		return nil, fmt.Errorf("profile not found with id: %s", id)
+	}
+	return profile, nil
+}
+
+func (s *ProfileService) defaultProfile(ctx context.Context, userID uuid.UUID) (*entity2.Profile, error) {
+	profile, err := s.profileRepo.GetDefaultByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default profile: %w", err)
+	}
+	if profile != nil {
+		return profile, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	if err := s.profileRepo.Create(ctx, newDefaultProfile(user)); err != nil {
+		return nil, fmt.Errorf("failed to create default profile: %w", err)
+	}
+
+	// Re-read in case a concurrent request created it first
+	profile, err = s.profileRepo.GetDefaultByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default profile: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("default profile missing for user: %s", userID)
+	}
+	return profile, nil
+}
+
+// newDefaultProfile builds the "self" profile every account starts with
+func newDefaultProfile(user *entity2.User) *entity2.Profile {
+	name := user.Email
+	if at := strings.Index(name, "@"); at > 0 {
+		name = name[:at]
+	}
+
+	return &entity2.Profile{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Name:        name,
+		Kind:        shared.ProfileSelf,
+		IsDefault:   true,
+		Timezone:    shared.DefaultTimezone,
+		Locale:      shared.DefaultLocale,
+		MorningTime: shared.DefaultSlotTimes[shared.Morning],
+		NoonTime:    shared.DefaultSlotTimes[shared.Noon],
+		EveningTime: shared.DefaultSlotTimes[shared.Evening],
+		NightTime:   shared.DefaultSlotTimes[shared.Night],
+		CreatedAt:   time.Now(),
+	}
+}
+
+func validateProfile(name string, kind shared.ProfileKind) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxProfileNameLength {
+		return fmt.Errorf("profile name must be between 1 and %d characters", maxProfileNameLength)
+	}
+
+	switch kind {
+	case shared.ProfileSelf, shared.ProfileChild, shared.ProfilePet, shared.ProfileOther:
+		return nil
+	default:
+		return fmt.Errorf("invalid profile kind: %s", kind)
+	}
+}
+
+// validateProfileSettings checks the settings present in req; omitted fields are not validated
+func validateProfileSettings(req *dto.ProfileSettings) error {
+	if req.Timezone != nil {
+		// time.LoadLocation also accepts "" and "Local", which mean nothing to the client
+		if *req.Timezone == "" || *req.Timezone == "Local" {
+			return fmt.Errorf("invalid timezone: %q", *req.Timezone)
+		}
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %q", *req.Timezone)
+		}
+	}
+
+	if req.Locale != nil && !localePattern.MatchString(*req.Locale) {
+		return fmt.Errorf("invalid locale: %q, expected a language tag such as en or de-CH", *req.Locale)
+	}
+
+	for slot, clock := range req.SlotTimes {
+		switch slot {
+		case shared.Morning, shared.Noon, shared.Evening, shared.Night:
+		default:
+			return fmt.Errorf("invalid time slot: %s", slot)
+		}
+		if !clockPattern.MatchString(clock) {
+			return fmt.Errorf("invalid time for %s: %q, expected HH:MM", slot, clock)
+		}
+	}
+
+	return nil
+}
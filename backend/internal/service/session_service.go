@@ -0,0 +1,73 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	"backend/internal/core/mapper"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionService struct {
+	sessionRepo SessionRepository
+}
+
+func NewSessionService(sessionRepo SessionRepository) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// IsSessionActive implements auth.SessionValidator
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return false, nil
+	}
+	return session.IsActive(time.Now()), nil
+}
+
+func (s *SessionService) GetByID(ctx context.Context, id uuid.UUID) (*dto.SessionResponse, error) {
+	session, err := s.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || !session.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return mapper.SessionFromEntity(session, uuid.Nil), nil
+}
+
+func (s *SessionService) ListActive(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	responses := make([]*dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = mapper.SessionFromEntity(session, currentSessionID)
+	}
+
+	return responses, nil
+}
+
+func (s *SessionService) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeOthers revokes every session of the user except the one making the request
+func (s *SessionService) RevokeOthers(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, userID, &currentSessionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
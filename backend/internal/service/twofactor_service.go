@@ -0,0 +1,192 @@
+package service
+
+import (
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService struct {
+	userRepo         UserRepository
+	recoveryCodeRepo RecoveryCodeRepository
+}
+
+func NewTwoFactorService(userRepo UserRepository, recoveryCodeRepo RecoveryCodeRepository) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+	}
+}
+
+// Enroll generates a new pending secret; 2FA stays off until Confirm succeeds
+func (s *TwoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorEnrollResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, &secret); err != nil {
+		return nil, fmt.Errorf("failed to store totp secret: %w", err)
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(config.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA once the user proves the authenticator works and returns fresh recovery codes
+func (s *TwoFactorService) Confirm(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorConfirmRequest) (*dto.TwoFactorConfirmResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return nil, fmt.Errorf("two-factor enrollment has not been started")
+	}
+
+	if err := s.verifyTOTP(ctx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.regenerateRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.EnableTOTP(ctx, userID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return &dto.TwoFactorConfirmResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off after checking the password and a current code or recovery code
+func (s *TwoFactorService) Disable(ctx context.Context, userID uuid.UUID, req *dto.TwoFactorDisableRequest) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return fmt.Errorf("password is incorrect")
+	}
+
+	if err := s.VerifySecondFactor(ctx, user, req.Code, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, nil); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// VerifySecondFactor accepts either a TOTP code or an unused recovery code for the user
+func (s *TwoFactorService) VerifySecondFactor(ctx context.Context, user *entity2.User, code, recoveryCode string) error {
+	if code != "" {
+		if err := s.verifyTOTP(ctx, user, code); err == nil {
+			return nil
+		}
+	}
+
+	if recoveryCode != "" {
+		hash := auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode))
+		consumed, err := s.recoveryCodeRepo.Consume(ctx, user.ID, hash, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to check recovery code: %w", err)
+		}
+		if consumed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid two-factor code")
+}
+
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, user *entity2.User, code string) error {
+	if user.TOTPSecret == nil {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	step, ok := auth.ValidateTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	// Each code is accepted once, so an observed code cannot be replayed within its window
+	fresh, err := s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record totp use: %w", err)
+	}
+	if !fresh {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) regenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	now := time.Now()
+	plain := make([]string, recoveryCodeCount)
+	codes := make([]*entity2.RecoveryCode, recoveryCodeCount)
+
+	for i := range plain {
+		code, err := auth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		plain[i] = code
+		codes[i] = &entity2.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  auth.HashToken(code),
+			CreatedAt: now,
+		}
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(ctx, userID, codes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return plain, nil
+}
+
+func (s *TwoFactorService) getUser(ctx context.Context, userID uuid.UUID) (*entity2.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found with id: %s", userID)
+	}
+	return user, nil
+}
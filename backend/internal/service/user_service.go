@@ -1,22 +1,37 @@
 package service
 
 import (
+	"backend/config"
+	"backend/internal/auth"
 	"backend/internal/core/dto"
 	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
+	"backend/internal/mailer"
 	"context"
 	"fmt"
+	"log"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
-	userRepo UserRepository
+	userRepo                UserRepository
+	sessionRepo             SessionRepository
+	personalAccessTokenRepo PersonalAccessTokenRepository
+	profileRepo             ProfileRepository
+	mailer                  mailer.Mailer
 }
 
-func NewUserService(userRepo UserRepository) *UserService {
+func NewUserService(userRepo UserRepository, sessionRepo SessionRepository, personalAccessTokenRepo PersonalAccessTokenRepository, profileRepo ProfileRepository, mailer mailer.Mailer) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:                userRepo,
+		sessionRepo:             sessionRepo,
+		personalAccessTokenRepo: personalAccessTokenRepo,
+		profileRepo:             profileRepo,
+		mailer:                  mailer,
 	}
 }
 
@@ -40,6 +55,10 @@ func (s *UserService) Create(ctx context.Context, req *dto.UserCreateRequest) (*
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if err := s.profileRepo.Create(ctx, newDefaultProfile(user)); err != nil {
+		return nil, fmt.Errorf("failed to create default profile: %w", err)
+	}
+
 	return mapper.UserFromEntity(user), nil
 }
 
@@ -66,3 +85,157 @@ func (s *UserService) GetByEmail(ctx context.Context, email string) (*dto.UserRe
 
 	return mapper.UserFromEntity(user), nil
 }
+
+// ChangePassword re-checks the current password, stores the new one, logs out every other session
+// and revokes every personal access token, which may have been created by whoever knew the old password
+func (s *UserService) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, req *dto.ChangePasswordRequest) error {
+	if len(req.NewPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, userID, &currentSessionID, now); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.personalAccessTokenRepo.RevokeAllByUserID(ctx, userID, now); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange re-checks the current password and sends a confirmation link to the new address.
+// The email is only changed once that link is opened.
+func (s *UserService) RequestEmailChange(ctx context.Context, userID uuid.UUID, req *dto.ChangeEmailRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found with id: %s", userID)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+
+	if req.NewEmail == user.Email {
+		return fmt.Errorf("new email must differ from the current email")
+	}
+
+	existingUser, err := s.userRepo.GetByEmail(ctx, req.NewEmail)
+	if err != nil {
+		return err
+	}
+	if existingUser != nil {
+		return fmt.Errorf("email already exists: %s", req.NewEmail)
+	}
+
+	token, err := auth.GenerateEmailChangeToken(user.ID, user.Email, req.NewEmail, config.EmailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/auth/confirm-email-change?token=%s", config.AppBaseURL, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      req.NewEmail,
+		Subject: "Confirm your new DoseLog email address",
+		Body: fmt.Sprintf("A request was made to use this address for your DoseLog account.\n\n"+
+			"Open the link below to confirm the change. It expires in %s.\n\n%s", config.EmailChangeTTL, link),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange applies a confirmed email change and logs out every session
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) error {
+	claims, err := auth.ValidateActionToken(token, auth.PurposeEmailChange)
+	if err != nil {
+		return fmt.Errorf("invalid or expired confirmation token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil || user.Email != claims.Subject {
+		return fmt.Errorf("invalid or expired confirmation token")
+	}
+
+	existingUser, err := s.userRepo.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return err
+	}
+	if existingUser != nil {
+		return fmt.Errorf("email already exists: %s", claims.Email)
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateEmail(ctx, user.ID, claims.Email, now); err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, user.ID, nil, now); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	notice := mailer.Message{
+		To:      user.Email,
+		Subject: "Your DoseLog email address was changed",
+		Body:    fmt.Sprintf("The email address of your DoseLog account was changed to %s.\n\nIf you did not make this change, contact support immediately.", claims.Email),
+	}
+	// The change is already applied, so a failed notice must not be reported as a failed confirmation
+	if err := s.mailer.Send(ctx, notice); err != nil {
+		log.Printf("failed to notify previous email address of user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (s *UserService) UpdateRole(ctx context.Context, id uuid.UUID, req *dto.UpdateRoleRequest) (*dto.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found with id: %s", id)
+	}
+
+	switch req.Role {
+	case shared.RoleUser, shared.RolePharmacist, shared.RoleAdmin:
+	default:
+		return nil, fmt.Errorf("invalid role: %s", req.Role)
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, id, req.Role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	user.Role = req.Role
+	return mapper.UserFromEntity(user), nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	r.users[id].Password = password
+	return nil
+}
+
+// revokingSessionRepository records which users had their sessions revoked; other methods panic
+type revokingSessionRepository struct {
+	SessionRepository
+	revoked []uuid.UUID
+}
+
+func (r *revokingSessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, exceptID *uuid.UUID, revokedAt time.Time) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// revokingTokenRepository records which users had their personal access tokens revoked; other methods panic
+type revokingTokenRepository struct {
+	PersonalAccessTokenRepository
+	revoked []uuid.UUID
+}
+
+func (r *revokingTokenRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+func TestChangePasswordRevokesPersonalAccessTokens(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &entity2.User{ID: uuid.New(), Email: "owner@example.com", Password: string(hash)}
+	userRepo := &memoryUserRepository{users: map[uuid.UUID]*entity2.User{user.ID: user}}
+	sessionRepo, tokenRepo := &revokingSessionRepository{}, &revokingTokenRepository{}
+	s := NewUserService(userRepo, sessionRepo, tokenRepo, nil, nil)
+
+	req := &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"}
+	if err := s.ChangePassword(context.Background(), user.ID, uuid.New(), req); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if len(sessionRepo.revoked) != 1 || len(tokenRepo.revoked) != 1 || tokenRepo.revoked[0] != user.ID {
+		t.Errorf("ChangePassword() revoked sessions of %v and tokens of %v, want both for %s", sessionRepo.revoked, tokenRepo.revoked, user.ID)
+	}
+}
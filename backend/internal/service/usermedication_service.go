@@ -2,7 +2,9 @@ package service
 
 import (
 	"backend/internal/core/dto"
+	entity2 "backend/internal/core/entity"
 	"backend/internal/core/mapper"
+	"backend/internal/core/shared"
 	"context"
 	"fmt"
 	"time"
@@ -10,17 +12,29 @@ import (
 	"github.com/google/uuid"
 )
 
+// Days of supply left at which stats report a "critical" or "warning" stock level
+const (
+	criticalSupplyDays = 5
+	lowSupplyDays      = 10
+)
+
 type UserMedicationService struct {
 	userMedicationRepo   UserMedicationRepository
 	medicationService    *MedicationService
 	medicationLogService *MedicationLogService
+	profileService       *ProfileService
+	auditService         *AuditService
+	interactionService   *InteractionService
 }
 
-func NewUserMedicationService(userMedicationRepo UserMedicationRepository, medicationService *MedicationService, medicationLogService *MedicationLogService) *UserMedicationService {
+func NewUserMedicationService(userMedicationRepo UserMedicationRepository, medicationService *MedicationService, medicationLogService *MedicationLogService, profileService *ProfileService, auditService *AuditService, interactionService *InteractionService) *UserMedicationService {
 	return &UserMedicationService{
 		userMedicationRepo:   userMedicationRepo,
 		medicationService:    medicationService,
 		medicationLogService: medicationLogService,
+		profileService:       profileService,
+		auditService:         auditService,
+		interactionService:   interactionService,
 	}
 }
 
@@ -35,38 +49,74 @@ func (s *UserMedicationService) GetByID(ctx context.Context, id uuid.UUID) (*dto
 	return mapper.UserMedicationFromEntity(userMedication), nil
 }
 
+// Create starts tracking a medication for one of the user's profiles, the default one unless req.ProfileID is set
 func (s *UserMedicationService) Create(ctx context.Context, userID uuid.UUID, req *dto.UserMedicationCreateRequest) (*dto.UserMedicationResponse, error) {
+	profile, err := s.resolveProfile(ctx, userID, req.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
 	medication, err := s.medicationService.GetByID(ctx, req.MedicationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get medication: %w", err)
 	}
+	// Users may track their own pending submissions and private entries, but nobody else's
+	if medication.Visibility == shared.VisibilityPrivate && (medication.OwnerID == nil || *medication.OwnerID != userID) ||
+		medication.Status != shared.MedicationApproved && (medication.SubmittedBy == nil || *medication.SubmittedBy != userID) {
+		return nil, fmt.Errorf("medication not found with id: %s", req.MedicationID)
+	}
+	if medication.ArchivedAt != nil {
+		return nil, fmt.Errorf("medication %s is archived and cannot be started", medication.Name)
+	}
+
+	userMedication := mapper.UserMedicationToEntity(userID, profile.ID, req)
 
-	totalPills := req.BoxesOwned * medication.PillsPerBox
-	var dailyConsumption float64
-	for _, schedule := range req.Schedules {
-		dailyConsumption += schedule.DoseAmount
+	dailyConsumption, err := resolveDoseUnits(medication, userMedication.Schedules)
+	if err != nil {
+		return nil, err
 	}
 
-	maxDays := int(float64(totalPills) / dailyConsumption)
+	totalQuantity := float64(req.BoxesOwned) * float64(medication.PackageSize)
+	maxDays := int(totalQuantity / dailyConsumption)
 
 	if req.DurationDays > maxDays {
-		return nil, fmt.Errorf("insufficient medication: you have %d pills (%.1f pills/day), maximum %d days available, but requested %d days",
-			totalPills, dailyConsumption, maxDays, req.DurationDays)
+		return nil, fmt.Errorf("insufficient medication: you have %g %s (%g %s/day), maximum %d days available, but requested %d days",
+			totalQuantity, medication.PackageUnit, dailyConsumption, medication.PackageUnit, maxDays, req.DurationDays)
 	}
 
-	userMedication := mapper.UserMedicationToEntity(userID, req)
+	warnings, err := s.checkInteractions(ctx, profile.ID, req.MedicationID)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := s.userMedicationRepo.Create(ctx, userMedication); err != nil {
 		return nil, fmt.Errorf("failed to create user medication: %w", err)
 	}
+	if err := s.auditService.Record(ctx, shared.AuditCreate, shared.AuditEntityUserMedication, userMedication.ID, &userMedication.UserID, nil, userMedication); err != nil {
+		return nil, err
+	}
 
 	if err := s.medicationLogService.CreateLogsForUserMedication(ctx, userMedication, req.DurationDays); err != nil {
 		return nil, fmt.Errorf("failed to generate medication logs: %w", err)
 	}
 
-	return mapper.UserMedicationFromEntity(userMedication), nil
+	response := mapper.UserMedicationFromEntity(userMedication)
+	response.InteractionWarnings = warnings
+	return response, nil
 }
 
+// checkInteractions compares a medication with the ones the profile is already taking.
+// Severe interactions refuse the tracking; milder ones are returned as warnings.
+func (s *UserMedicationService) checkInteractions(ctx context.Context, profileID, medicationID uuid.UUID) ([]dto.InteractionWarning, error) {
+	active, err := s.userMedicationRepo.GetActiveByProfileID(ctx, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active user medications: %w", err)
+	}
+
+	return s.interactionService.CheckActive(ctx, medicationID, active)
+}
+
+// Update changes a tracking. Resuming a paused one checks its interactions again, as starting it does.
 func (s *UserMedicationService) Update(ctx context.Context, id uuid.UUID, req *dto.UserMedicationUpdateRequest) (*dto.UserMedicationResponse, error) {
 	userMedication, err := s.userMedicationRepo.GetByID(ctx, id)
 	if err != nil {
@@ -76,17 +126,39 @@ func (s *UserMedicationService) Update(ctx context.Context, id uuid.UUID, req *d
 		return nil, fmt.Errorf("user medication not found with id: %s", id)
 	}
 
+	before := *userMedication
 	mapper.UpdateUserMedicationEntity(userMedication, req)
+	if req.Schedules != nil {
+		medication, err := s.medicationService.GetByID(ctx, userMedication.MedicationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get medication: %w", err)
+		}
+		if _, err := resolveDoseUnits(medication, userMedication.Schedules); err != nil {
+			return nil, err
+		}
+	}
+
+	var warnings []dto.InteractionWarning
+	if !before.Active && userMedication.Active {
+		if warnings, err = s.checkInteractions(ctx, userMedication.ProfileID, userMedication.MedicationID); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.userMedicationRepo.Update(ctx, userMedication); err != nil {
 		return nil, fmt.Errorf("failed to update user medication: %w", err)
 	}
+	if err := s.auditService.Record(ctx, shared.AuditUpdate, shared.AuditEntityUserMedication, userMedication.ID, &userMedication.UserID, &before, userMedication); err != nil {
+		return nil, err
+	}
 
-	return mapper.UserMedicationFromEntity(userMedication), nil
+	response := mapper.UserMedicationFromEntity(userMedication)
+	response.InteractionWarnings = warnings
+	return response, nil
 }
 
-func (s *UserMedicationService) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*dto.UserMedicationResponse, error) {
-	userMedications, err := s.userMedicationRepo.GetByUserID(ctx, userID)
+func (s *UserMedicationService) GetByProfileID(ctx context.Context, profileID uuid.UUID) ([]*dto.UserMedicationResponse, error) {
+	userMedications, err := s.userMedicationRepo.GetByProfileID(ctx, profileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user medications: %w", err)
 	}
@@ -99,8 +171,8 @@ func (s *UserMedicationService) GetByUserID(ctx context.Context, userID uuid.UUI
 	return responses, nil
 }
 
-func (s *UserMedicationService) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*dto.UserMedicationResponse, error) {
-	userMedications, err := s.userMedicationRepo.GetActiveByUserID(ctx, userID)
+func (s *UserMedicationService) GetActiveByProfileID(ctx context.Context, profileID uuid.UUID) ([]*dto.UserMedicationResponse, error) {
+	userMedications, err := s.userMedicationRepo.GetActiveByProfileID(ctx, profileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active user medications: %w", err)
 	}
@@ -132,33 +204,38 @@ func (s *UserMedicationService) GetStats(ctx context.Context, id uuid.UUID) (*dt
 		return nil, fmt.Errorf("failed to get logs: %w", err)
 	}
 
-	totalPills := userMedication.BoxesOwned * medication.PillsPerBox
+	totalQuantity := float64(userMedication.BoxesOwned) * float64(medication.PackageSize)
 
-	var dailyConsumption float64
-	for _, schedule := range userMedication.Schedules {
-		dailyConsumption += schedule.DoseAmount
+	dailyConsumption, err := resolveDoseUnits(medication, userMedication.Schedules)
+	if err != nil {
+		return nil, err
 	}
 
-	var usedPills float64
+	var usedQuantity float64
 	for _, log := range logs {
 		if log.Taken {
-			usedPills += log.PlannedDose
+			used, err := toPackageUnits(medication, log.PlannedDose, log.DoseUnit)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dose in log %s: %w", log.ID, err)
+			}
+			usedQuantity += used
 		}
 	}
 
-	remainingPills := float64(totalPills) - usedPills
+	remainingQuantity := totalQuantity - usedQuantity
 
 	var estimatedDaysRemaining int
 	var estimatedEndDate time.Time
 	if dailyConsumption > 0 {
-		estimatedDaysRemaining = int(remainingPills / dailyConsumption)
+		estimatedDaysRemaining = int(remainingQuantity / dailyConsumption)
 		estimatedEndDate = time.Now().AddDate(0, 0, estimatedDaysRemaining)
 	}
 
+	// Stock is judged in days of supply, so the levels mean the same for pills, syrups and inhalers
 	warningLevel := "normal"
-	if remainingPills <= 10 {
+	if estimatedDaysRemaining <= criticalSupplyDays {
 		warningLevel = "critical"
-	} else if remainingPills <= 20 {
+	} else if estimatedDaysRemaining <= lowSupplyDays {
 		warningLevel = "warning"
 	}
 
@@ -169,9 +246,10 @@ func (s *UserMedicationService) GetStats(ctx context.Context, id uuid.UUID) (*dt
 	}
 
 	return &dto.UserMedicationStatsResponse{
-		TotalPills:             totalPills,
-		UsedPills:              usedPills,
-		RemainingPills:         remainingPills,
+		Unit:                   medication.PackageUnit,
+		TotalQuantity:          totalQuantity,
+		UsedQuantity:           usedQuantity,
+		RemainingQuantity:      remainingQuantity,
 		DailyConsumption:       dailyConsumption,
 		EstimatedDaysRemaining: estimatedDaysRemaining,
 		EstimatedEndDate:       estimatedEndDate,
@@ -179,5 +257,50 @@ func (s *UserMedicationService) GetStats(ctx context.Context, id uuid.UUID) (*dt
 		DaysElapsed:            daysElapsed,
 		PlannedDaysRemaining:   plannedDaysRemaining,
 		WarningLevel:           warningLevel,
+		TotalPills:             totalQuantity,
+		UsedPills:              usedQuantity,
+		RemainingPills:         remainingQuantity,
 	}, nil
 }
+
+// resolveDoseUnits defaults schedule dose units to the package unit and returns how much of the
+// package the schedules consume per day
+func resolveDoseUnits(medication *dto.MedicationResponse, schedules []entity2.IntakeSchedule) (float64, error) {
+	var daily float64
+	for i := range schedules {
+		if schedules[i].DoseUnit == "" {
+			schedules[i].DoseUnit = medication.PackageUnit
+		}
+		if schedules[i].DoseAmount <= 0 {
+			return 0, fmt.Errorf("%s dose_amount must be greater than 0", schedules[i].TimeSlot)
+		}
+		amount, err := toPackageUnits(medication, schedules[i].DoseAmount, schedules[i].DoseUnit)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s dose: %w", schedules[i].TimeSlot, err)
+		}
+		daily += amount
+	}
+	if daily <= 0 {
+		return 0, fmt.Errorf("at least one schedule is required")
+	}
+	return daily, nil
+}
+
+func toPackageUnits(medication *dto.MedicationResponse, amount float64, unit shared.DoseUnit) (float64, error) {
+	return shared.ConvertToPackageUnits(amount, unit, medication.PackageUnit, float64(medication.Strength), medication.StrengthUnit)
+}
+
+func (s *UserMedicationService) resolveProfile(ctx context.Context, userID uuid.UUID, profileID *uuid.UUID) (*dto.ProfileResponse, error) {
+	if profileID == nil {
+		return s.profileService.Default(ctx, userID)
+	}
+
+	profile, err := s.profileService.GetByID(ctx, *profileID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil || profile.UserID != userID {
+		return nil, fmt.Errorf("profile not found with id: %s", *profileID)
+	}
+	return profile, nil
+}
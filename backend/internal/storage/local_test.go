@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoragePathRejectsEscapingKeys(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+
+	for _, key := range []string{"", "..", "../etc/passwd", "medications/../../etc/passwd", "/etc/passwd"} {
+		if path, err := s.path(key); err == nil {
+			t.Errorf("path(%q) = %s, want an error", key, path)
+		}
+	}
+
+	for _, key := range []string{"medications/a/b", "medications/../medications/a", "./a"} {
+		if _, err := s.path(key); err != nil {
+			t.Errorf("path(%q) error = %v", key, err)
+		}
+	}
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir)
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "medications/a/b", []byte("leaflet"), "application/pdf"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "medications", "a", "b")); err != nil {
+		t.Fatalf("Put() did not write below the storage directory: %v", err)
+	}
+
+	reader, err := s.Get(ctx, "medications/a/b")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "leaflet" {
+		t.Errorf("Get() = %q, want %q", data, "leaflet")
+	}
+
+	if err := s.Delete(ctx, "medications/a/b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := s.Delete(ctx, "medications/a/b"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+	if _, err := s.Get(ctx, "medications/a/b"); err != ErrNotFound {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put(ctx, "../outside", []byte("x"), ""); err == nil {
+		t.Error("Put() accepted a key outside the storage directory")
+	}
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"backend/config"
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files such as medication attachments. Keys are slash-separated paths
+// generated by the application, never taken from user input.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the storage selected by config.StorageDriver
+func New() Storage {
+	switch config.StorageDriver {
+	case "s3":
+		return NewS3Storage(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKeyID, config.S3SecretAccessKey, config.S3ForcePathStyle)
+	default:
+		return NewLocalStorage(config.StorageLocalDir)
+	}
+}
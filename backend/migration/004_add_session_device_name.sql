@@ -0,0 +1,11 @@
+BEGIN;
+
+-- ==========================================================
+-- ADD device_name COLUMN TO sessions TABLE
+-- ==========================================================
+ALTER TABLE sessions
+ADD COLUMN device_name TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active ON sessions(user_id) WHERE revoked_at IS NULL;
+
+COMMIT;
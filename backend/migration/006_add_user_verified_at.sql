@@ -0,0 +1,12 @@
+BEGIN;
+
+-- ==========================================================
+-- ADD verified_at COLUMN TO users TABLE
+-- ==========================================================
+ALTER TABLE users
+ADD COLUMN verified_at TIMESTAMPTZ;
+
+-- Existing accounts were active under the old rules, so they count as verified
+UPDATE users SET verified_at = created_at WHERE verified_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+-- ==========================================================
+-- ACCOUNT_DELETION_REQUESTS TABLE (Deletion workflow)
+-- ==========================================================
+-- user_id intentionally has no foreign key: rows must outlive the
+-- account so that completed deletions remain auditable.
+CREATE TABLE IF NOT EXISTS account_deletion_requests (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    requested_ip TEXT,
+    requested_at TIMESTAMPTZ DEFAULT now(),
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    cancelled_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+    );
+
+CREATE INDEX IF NOT EXISTS idx_account_deletion_requests_user_id ON account_deletion_requests(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_deletion_requests_pending
+    ON account_deletion_requests(user_id) WHERE cancelled_at IS NULL AND completed_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- ==========================================================
+-- ADD TOTP COLUMNS TO users TABLE
+-- ==========================================================
+ALTER TABLE users
+ADD COLUMN totp_secret TEXT,
+ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+ADD COLUMN totp_last_step BIGINT;
+
+-- ==========================================================
+-- RECOVERY_CODES TABLE (One-time 2FA fallback codes)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT uniq_recovery_code UNIQUE (user_id, code_hash)
+    );
+
+COMMIT;
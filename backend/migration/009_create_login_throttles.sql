@@ -0,0 +1,16 @@
+BEGIN;
+
+-- ==========================================================
+-- LOGIN_THROTTLES TABLE (Failed login tracking per account and per IP)
+-- ==========================================================
+-- key is "account:<email>" or "ip:<address>"
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ
+    );
+
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure_at ON login_throttles(last_failure_at);
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- ==========================================================
+-- ADD role COLUMN TO users TABLE
+-- ==========================================================
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'pharmacist', 'admin'));
+
+-- ==========================================================
+-- ADD REVIEW COLUMNS TO medications TABLE
+-- ==========================================================
+-- Existing catalog entries are treated as already approved.
+ALTER TABLE medications
+ADD COLUMN status TEXT NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('pending', 'approved', 'rejected')),
+ADD COLUMN submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_medications_status ON medications(status);
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+-- ==========================================================
+-- CARE_GRANTS TABLE (Caregiver access to a patient's data)
+-- ==========================================================
+-- caregiver_id stays NULL until the invited address accepts the invite.
+CREATE TABLE IF NOT EXISTS care_grants (
+    id UUID PRIMARY KEY,
+    patient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    caregiver_email TEXT NOT NULL,
+    caregiver_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL CHECK (permission IN ('read', 'manage')),
+    invite_token_hash TEXT UNIQUE NOT NULL,
+    invite_expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_care_grants_patient_id ON care_grants(patient_id);
+CREATE INDEX IF NOT EXISTS idx_care_grants_caregiver_id ON care_grants(caregiver_id);
+
+-- At most one live grant per patient/caregiver pair
+CREATE UNIQUE INDEX IF NOT EXISTS idx_care_grants_active_pair
+    ON care_grants(patient_id, caregiver_id)
+    WHERE caregiver_id IS NOT NULL AND revoked_at IS NULL;
+
+-- ==========================================================
+-- RECORD WHO MARKED A DOSE AS TAKEN
+-- ==========================================================
+ALTER TABLE medication_logs
+ADD COLUMN taken_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN taken_at TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,55 @@
+BEGIN;
+
+-- ==========================================================
+-- PROFILES TABLE (People or pets tracked under one account)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS profiles (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'self'
+        CHECK (kind IN ('self', 'child', 'pet', 'other')),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_profiles_user_id ON profiles(user_id);
+
+-- Every account has exactly one default profile
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profiles_default
+    ON profiles(user_id)
+    WHERE is_default;
+
+-- ==========================================================
+-- DEFAULT PROFILE FOR EXISTING USERS
+-- ==========================================================
+INSERT INTO profiles (id, user_id, name, kind, is_default, created_at)
+SELECT gen_random_uuid(), u.id, split_part(u.email, '@', 1), 'self', TRUE, u.created_at
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM profiles p WHERE p.user_id = u.id AND p.is_default);
+
+-- ==========================================================
+-- MOVE user_medications OWNERSHIP TO PROFILES
+-- ==========================================================
+-- user_id is kept and always equals the owning account of the profile,
+-- so access checks do not need an extra join.
+ALTER TABLE user_medications
+ADD COLUMN profile_id UUID REFERENCES profiles(id) ON DELETE CASCADE;
+
+UPDATE user_medications um
+SET profile_id = p.id
+FROM profiles p
+WHERE p.user_id = um.user_id AND p.is_default AND um.profile_id IS NULL;
+
+ALTER TABLE user_medications
+ALTER COLUMN profile_id SET NOT NULL;
+
+ALTER TABLE user_medications
+DROP CONSTRAINT IF EXISTS uniq_user_medication;
+
+ALTER TABLE user_medications
+ADD CONSTRAINT uniq_profile_medication UNIQUE (profile_id, medication_id);
+
+CREATE INDEX IF NOT EXISTS idx_user_medications_profile_id ON user_medications(profile_id);
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+-- ==========================================================
+-- OIDC_LOGIN_STATES TABLE (Pending authorization requests)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    device_name TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
+
+-- ==========================================================
+-- USER_IDENTITIES TABLE (External accounts linked to users)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    last_login_at TIMESTAMPTZ,
+    CONSTRAINT uniq_user_identity UNIQUE (provider, subject)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- ==========================================================
+-- PROFILE SCHEDULE SETTINGS (Timezone, locale, slot clock times)
+-- ==========================================================
+-- Slot times are wall-clock times (HH:MM) in the profile's timezone.
+ALTER TABLE profiles
+ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC',
+ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en',
+ADD COLUMN IF NOT EXISTS morning_time TEXT NOT NULL DEFAULT '08:00'
+    CHECK (morning_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+ADD COLUMN IF NOT EXISTS noon_time TEXT NOT NULL DEFAULT '12:00'
+    CHECK (noon_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+ADD COLUMN IF NOT EXISTS evening_time TEXT NOT NULL DEFAULT '18:00'
+    CHECK (evening_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+ADD COLUMN IF NOT EXISTS night_time TEXT NOT NULL DEFAULT '22:00'
+    CHECK (night_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$');
+
+COMMIT;
@@ -0,0 +1,44 @@
+BEGIN;
+
+-- ==========================================================
+-- AUDIT EVENTS TABLE (Append-only trail of data mutations)
+-- ==========================================================
+-- No foreign keys: events outlive the users and rows they describe.
+-- subject_user_id is the account whose data changed, actor_id the one who changed it
+-- (they differ for caregivers and catalog reviewers).
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor_id UUID,
+    subject_user_id UUID,
+    session_id UUID,
+    token_id UUID,
+    ip_address TEXT,
+    user_agent TEXT,
+    action TEXT NOT NULL
+        CHECK (action IN ('create', 'update', 'delete')),
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    before JSONB,
+    after JSONB,
+    changes JSONB
+    );
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_subject ON audit_events(subject_user_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id);
+
+-- Rows may be deleted by the retention job but never changed
+CREATE OR REPLACE FUNCTION audit_events_reject_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events;
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_reject_update();
+
+COMMIT;
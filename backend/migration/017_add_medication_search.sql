@@ -0,0 +1,26 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION CATALOG SEARCH
+-- ==========================================================
+-- Trigram indexes serve both fuzzy (%) and substring (LIKE) matches on lower(name) and lower(manufacturer)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_medications_name_trgm
+    ON medications USING GIN (lower(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_medications_manufacturer_trgm
+    ON medications USING GIN (lower(manufacturer) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_medications_form ON medications(form);
+CREATE INDEX IF NOT EXISTS idx_medications_meal_relation ON medications(meal_relation);
+
+-- Keyset paging over the approved catalog for each sortable field
+CREATE INDEX IF NOT EXISTS idx_medications_approved_created_at
+    ON medications(created_at, id) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_medications_approved_name
+    ON medications(lower(name), id) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_medications_approved_strength
+    ON medications(COALESCE(strength_mg, 0), id) WHERE status = 'approved';
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION EXTERNAL CODE (Identifier from an imported source catalog)
+-- ==========================================================
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS external_code TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_external_code
+    ON medications(external_code)
+    WHERE external_code IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,44 @@
+BEGIN;
+
+-- ==========================================================
+-- INGREDIENTS TABLE (Active substances)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS ingredients (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ingredients_name ON ingredients(lower(name));
+
+-- ==========================================================
+-- MEDICATION_INGREDIENTS TABLE (What a catalog entry contains)
+-- ==========================================================
+CREATE TABLE IF NOT EXISTS medication_ingredients (
+    medication_id UUID NOT NULL REFERENCES medications(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    amount_mg REAL CHECK (amount_mg > 0),
+    PRIMARY KEY (medication_id, ingredient_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_medication_ingredients_ingredient_id ON medication_ingredients(ingredient_id);
+
+-- ==========================================================
+-- INGREDIENT_INTERACTIONS TABLE (Known drug-drug interactions)
+-- ==========================================================
+-- Each pair is stored once with ingredient_a_id < ingredient_b_id.
+-- Loaded from a reference file with the import-interactions command or the admin endpoint.
+CREATE TABLE IF NOT EXISTS ingredient_interactions (
+    ingredient_a_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    ingredient_b_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    severity TEXT NOT NULL
+        CHECK (severity IN ('minor', 'moderate', 'severe')),
+    description TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (ingredient_a_id, ingredient_b_id),
+    CHECK (ingredient_a_id < ingredient_b_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_ingredient_interactions_b ON ingredient_interactions(ingredient_b_id);
+
+COMMIT;
@@ -0,0 +1,73 @@
+BEGIN;
+
+-- ==========================================================
+-- DOSE UNITS (Strength and package size for non-pill forms)
+-- ==========================================================
+-- strength is the amount of active ingredient per package unit: per tablet, per ml, per drop, per puff
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS strength REAL,
+ADD COLUMN IF NOT EXISTS strength_unit TEXT NOT NULL DEFAULT 'mg'
+    CHECK (strength_unit IN ('mg', 'mcg', 'g', 'iu'));
+
+UPDATE medications SET strength = strength_mg WHERE strength IS NULL;
+
+-- strength_mg stays available, normalised to mg, for catalog search and sorting; IU strengths have none
+DROP INDEX IF EXISTS idx_medications_approved_strength;
+ALTER TABLE medications DROP COLUMN IF EXISTS strength_mg;
+ALTER TABLE medications
+ADD COLUMN strength_mg REAL GENERATED ALWAYS AS (
+    CASE strength_unit
+        WHEN 'mg' THEN strength
+        WHEN 'mcg' THEN strength / 1000
+        WHEN 'g' THEN strength * 1000
+    END
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_medications_approved_strength
+    ON medications(COALESCE(strength_mg, 0), id) WHERE status = 'approved';
+
+-- pills_per_box becomes the package size, counted in package_unit (30 units, 100 ml, 200 puffs)
+ALTER TABLE medications RENAME COLUMN pills_per_box TO package_size;
+ALTER TABLE medications ALTER COLUMN package_size TYPE REAL;
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS package_unit TEXT;
+
+UPDATE medications
+SET package_unit = CASE form
+    WHEN 'syrup' THEN 'ml'
+    WHEN 'injection' THEN 'ml'
+    WHEN 'drop' THEN 'drops'
+    ELSE 'units'
+END
+WHERE package_unit IS NULL;
+
+ALTER TABLE medications
+ALTER COLUMN package_unit SET NOT NULL,
+ADD CONSTRAINT medications_package_unit_check
+    CHECK (package_unit IN ('units', 'ml', 'drops', 'puffs', 'g'));
+
+-- Doses are kept in the unit they were prescribed in; existing ones were in package units
+ALTER TABLE medication_logs
+ADD COLUMN IF NOT EXISTS dose_unit TEXT;
+
+UPDATE medication_logs ml
+SET dose_unit = m.package_unit
+FROM user_medications um
+JOIN medications m ON m.id = um.medication_id
+WHERE um.id = ml.user_medication_id
+  AND ml.dose_unit IS NULL;
+
+ALTER TABLE medication_logs
+ALTER COLUMN dose_unit SET NOT NULL;
+
+UPDATE user_medications um
+SET schedules = (
+    SELECT jsonb_agg(s || jsonb_build_object('dose_unit', m.package_unit))
+    FROM jsonb_array_elements(um.schedules) s
+)
+FROM medications m
+WHERE m.id = um.medication_id
+  AND jsonb_typeof(um.schedules) = 'array'
+  AND jsonb_array_length(um.schedules) > 0;
+
+COMMIT;
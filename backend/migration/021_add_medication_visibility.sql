@@ -0,0 +1,28 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION VISIBILITY (Private user-defined entries next to the shared catalog)
+-- ==========================================================
+-- Private entries (a compounded prescription, a foreign brand) are only seen by their owner
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'shared'
+    CHECK (visibility IN ('shared', 'private')),
+ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE medications
+ADD CONSTRAINT medications_private_owner_check
+    CHECK ((visibility = 'private') = (owner_id IS NOT NULL));
+
+-- Names are unique within the shared catalog and within each user's private entries,
+-- so a private entry may reuse the name of a shared one
+DROP INDEX IF EXISTS idx_medications_name;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_shared_name
+    ON medications(name)
+    WHERE visibility = 'shared';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_private_name
+    ON medications(owner_id, name)
+    WHERE visibility = 'private';
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION ARCHIVAL (Hidden from search, still resolvable by id)
+-- ==========================================================
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Search only ever looks at entries that are not archived
+DROP INDEX IF EXISTS idx_medications_approved_created_at;
+DROP INDEX IF EXISTS idx_medications_approved_name;
+DROP INDEX IF EXISTS idx_medications_approved_strength;
+
+CREATE INDEX IF NOT EXISTS idx_medications_approved_created_at
+    ON medications(created_at, id) WHERE status = 'approved' AND archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_medications_approved_name
+    ON medications(lower(name), id) WHERE status = 'approved' AND archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_medications_approved_strength
+    ON medications(COALESCE(strength_mg, 0), id) WHERE status = 'approved' AND archived_at IS NULL;
+
+COMMIT;
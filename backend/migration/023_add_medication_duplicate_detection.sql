@@ -0,0 +1,26 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION DUPLICATE DETECTION
+-- ==========================================================
+-- normalize_medication_name reduces a name to the words that identify the drug: lower case,
+-- punctuation, numbers and unit or form words removed. "Ibuprofen 400" and "ibuprofen 400 mg"
+-- both become "ibuprofen"; strength and form are compared separately.
+CREATE OR REPLACE FUNCTION normalize_medication_name(name TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT btrim(regexp_replace(
+        regexp_replace(
+            regexp_replace(lower(name), '[^[:alnum:]]+', ' ', 'g'),
+            '\m([0-9]+|mg|mcg|g|ml|iu|units?|tablets?|tabs?|capsules?|caps?|syrup|drops?|injections?|film|coated)\M', ' ', 'g'),
+        '\s+', ' ', 'g'))
+$$;
+
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS normalized_name TEXT
+    GENERATED ALWAYS AS (normalize_medication_name(name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_medications_normalized_name ON medications(normalized_name);
+CREATE INDEX IF NOT EXISTS idx_medications_normalized_name_trgm
+    ON medications USING GIN (normalized_name gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION_ATTACHMENTS TABLE (Pill and box photos, package leaflets)
+-- ==========================================================
+-- The files themselves live in the configured storage backend under storage_key;
+-- images also get a JPEG thumbnail under thumbnail_key.
+CREATE TABLE IF NOT EXISTS medication_attachments (
+    id UUID PRIMARY KEY,
+    medication_id UUID NOT NULL REFERENCES medications(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL
+        CHECK (kind IN ('image', 'leaflet')),
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    storage_key TEXT NOT NULL UNIQUE,
+    thumbnail_key TEXT,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_medication_attachments_medication_id ON medication_attachments(medication_id);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- ==========================================================
+-- MEDICATION BARCODES (GTIN/EAN codes printed on the box)
+-- ==========================================================
+-- Codes are stored as 14-digit GTINs, so EAN-13, UPC-A and GTIN-8 scans of the
+-- same product compare equal. A box may carry several codes, e.g. per market.
+ALTER TABLE medications
+ADD COLUMN IF NOT EXISTS barcodes TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_medications_barcodes ON medications USING GIN (barcodes);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- ==========================================================
+-- SCOPE MEDICATION EXTERNAL CODE (Like names: per shared catalog and per owner)
+-- ==========================================================
+-- A code held by someone's private entry must neither block nor be matched by
+-- the shared catalog, nor reveal that the private entry exists
+DROP INDEX IF EXISTS idx_medications_external_code;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_shared_external_code
+    ON medications(external_code)
+    WHERE visibility = 'shared' AND external_code IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_private_external_code
+    ON medications(owner_id, external_code)
+    WHERE visibility = 'private' AND external_code IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- ==========================================================
+-- KEEP TRACKED MEDICATIONS (Refuse deleting a catalog entry a user medication references)
+-- ==========================================================
+-- Deleting a catalog entry used to cascade to its trackings and their logs. The
+-- application checks for references first, but only the database can stop a tracking
+-- started concurrently from being lost. NO ACTION is checked at the end of the
+-- statement, so deleting a user, which removes their private entries and their
+-- trackings in one statement, still works.
+ALTER TABLE user_medications
+DROP CONSTRAINT IF EXISTS user_medications_medication_id_fkey;
+
+ALTER TABLE user_medications
+ADD CONSTRAINT user_medications_medication_id_fkey
+    FOREIGN KEY (medication_id) REFERENCES medications(id) ON DELETE NO ACTION;
+
+COMMIT;
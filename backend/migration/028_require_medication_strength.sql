@@ -0,0 +1,16 @@
+BEGIN;
+
+-- ==========================================================
+-- REQUIRED STRENGTH (Strength is never NULL after the dose unit backfill)
+-- ==========================================================
+-- 020 copied strength_mg into strength but left the column nullable, and entries created
+-- before strength_mg was required still have none. The application reads strength as a
+-- number, so such rows could not be loaded at all. They get 0, which unit conversions
+-- already treat as an unknown strength, until a pharmacist fills it in.
+UPDATE medications SET strength = 0 WHERE strength IS NULL;
+
+ALTER TABLE medications
+ALTER COLUMN strength SET NOT NULL,
+ADD CONSTRAINT medications_strength_check CHECK (strength >= 0);
+
+COMMIT;